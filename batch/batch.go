@@ -0,0 +1,125 @@
+package batch
+
+import "errors"
+
+// ErrConflict is returned by Txn.Commit when a key the transaction read was changed before it committed
+var ErrConflict = errors.New("transaction conflict: a key it read has changed")
+
+// Batch collects inserts and deletes so they can be applied to a store in one go with the store's Apply
+type Batch[T any] struct {
+	ops []op[T]
+}
+
+type op[T any] struct {
+	key    string
+	value  T
+	delete bool
+}
+
+// Insert queues setting key to val
+func (b *Batch[T]) Insert(key string, val T) {
+	b.ops = append(b.ops, op[T]{key: key, value: val})
+}
+
+// Delete queues removing key
+func (b *Batch[T]) Delete(key string) {
+	b.ops = append(b.ops, op[T]{key: key, delete: true})
+}
+
+// Len returns the number of queued operations
+func (b *Batch[T]) Len() int {
+	return len(b.ops)
+}
+
+// Each calls fn for every queued operation in the order they were queued, with isDelete set for deletes.
+// This is how a store's Apply gets at the operations.
+func (b *Batch[T]) Each(fn func(key string, val T, isDelete bool)) {
+	for _, op := range b.ops {
+		fn(op.key, op.value, op.delete)
+	}
+}
+
+// Txn is an optimistic transaction: it buffers its writes and records what it saw of every key it reads.
+// Commit only applies the writes if none of those keys have changed in the meantime, so a transaction
+// that read half of a subtree before someone else updated it fails instead of writing a mixed result.
+type Txn[T any] struct {
+	read  func(key string) (bool, T, uint64)
+	apply func(b *Batch[T])
+	// same reports whether two reads of a key saw the same thing
+	same func(first, now snapshot[T]) bool
+	// what each key looked like when the transaction first read it
+	reads map[string]snapshot[T]
+	// pending writes by key, so the transaction reads back what it wrote
+	pending map[string]op[T]
+	writes  Batch[T]
+}
+
+type snapshot[T any] struct {
+	exists  bool
+	value   T
+	version uint64
+}
+
+// Begin starts an optimistic transaction against a store. read returns whether a key exists, its value and its
+// version, which has to be at least 1 and change on every write to the key; apply applies a batch to the store.
+func Begin[T any](read func(key string) (bool, T, uint64), apply func(b *Batch[T])) *Txn[T] {
+	return newTxn(read, apply, func(first, now snapshot[T]) bool {
+		return first.exists == now.exists && (!first.exists || first.version == now.version)
+	})
+}
+
+// BeginByValue starts an optimistic transaction against a store that doesn't keep versions. A key counts as changed
+// if its value did, so a key that changed and then changed back isn't a conflict.
+func BeginByValue[T comparable](read func(key string) (bool, T), apply func(b *Batch[T])) *Txn[T] {
+	readUnversioned := func(key string) (bool, T, uint64) {
+		exists, val := read(key)
+		return exists, val, 0
+	}
+	return newTxn(readUnversioned, apply, func(first, now snapshot[T]) bool {
+		return first.exists == now.exists && first.value == now.value
+	})
+}
+
+func newTxn[T any](read func(key string) (bool, T, uint64), apply func(b *Batch[T]), same func(first, now snapshot[T]) bool) *Txn[T] {
+	return &Txn[T]{read: read, apply: apply, same: same, reads: make(map[string]snapshot[T]), pending: make(map[string]op[T])}
+}
+
+// Get returns the value for key as the transaction sees it, and adds key to the read set
+func (tx *Txn[T]) Get(key string) (bool, T) {
+	if op, ok := tx.pending[key]; ok {
+		return !op.delete, op.value
+	}
+	exists, val, version := tx.read(key)
+	// only the first read counts: that's what the transaction based its decisions on
+	if _, seen := tx.reads[key]; !seen {
+		tx.reads[key] = snapshot[T]{exists, val, version}
+	}
+	return exists, val
+}
+
+// Insert buffers setting key to val until Commit
+func (tx *Txn[T]) Insert(key string, val T) {
+	tx.pending[key] = op[T]{key: key, value: val}
+	tx.writes.Insert(key, val)
+}
+
+// Delete buffers removing key until Commit
+func (tx *Txn[T]) Delete(key string) {
+	tx.pending[key] = op[T]{key: key, delete: true}
+	tx.writes.Delete(key)
+}
+
+// Commit applies the transaction's writes if nothing it read has changed since, and returns ErrConflict otherwise.
+// Either way nothing is left half-written. A Txn can't be used again after Commit.
+// Checking the read set and applying the writes are two steps, so Commit needs the same exclusive access to the
+// store as its Apply.
+func (tx *Txn[T]) Commit() error {
+	for key, first := range tx.reads {
+		exists, val, version := tx.read(key)
+		if !tx.same(first, snapshot[T]{exists, val, version}) {
+			return ErrConflict
+		}
+	}
+	tx.apply(&tx.writes)
+	return nil
+}
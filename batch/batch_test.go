@@ -0,0 +1,135 @@
+package batch
+
+import (
+	"reflect"
+	"testing"
+)
+
+// versionedMap is the smallest store a Txn can run against
+type versionedMap struct {
+	values   map[string]int
+	versions map[string]uint64
+	version  uint64
+}
+
+func newVersionedMap() *versionedMap {
+	return &versionedMap{values: make(map[string]int), versions: make(map[string]uint64)}
+}
+
+func (m *versionedMap) insert(key string, val int) {
+	m.version++
+	m.values[key] = val
+	m.versions[key] = m.version
+}
+
+func (m *versionedMap) read(key string) (bool, int, uint64) {
+	val, ok := m.values[key]
+	return ok, val, m.versions[key]
+}
+
+func (m *versionedMap) apply(b *Batch[int]) {
+	b.Each(func(key string, val int, isDelete bool) {
+		if isDelete {
+			delete(m.values, key)
+			delete(m.versions, key)
+		} else {
+			m.insert(key, val)
+		}
+	})
+}
+
+func TestBatch(t *testing.T) {
+	var b Batch[int]
+	b.Insert("net", 4)
+	b.Delete("taxes")
+	b.Insert("net", 5)
+	if b.Len() != 3 {
+		t.Errorf("expected 3 queued operations, got %d", b.Len())
+	}
+	var got []string
+	b.Each(func(key string, val int, isDelete bool) {
+		if isDelete {
+			got = append(got, "-"+key)
+		} else {
+			got = append(got, key)
+		}
+	})
+	if expected := []string{"net", "-taxes", "net"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v in order, got %v", expected, got)
+	}
+}
+
+func TestTxn(t *testing.T) {
+	m := newVersionedMap()
+	m.insert("net", 3)
+
+	tx := Begin(m.read, m.apply)
+	_, net := tx.Get("net")
+	tx.Insert("net", net+1)
+	tx.Delete("gross")
+	// the transaction reads back its own writes
+	if found, val := tx.Get("net"); !found || val != 4 {
+		t.Errorf("expected to read back 4, got %v %d", found, val)
+	}
+	if found, _ := tx.Get("gross"); found {
+		t.Errorf("expected a pending delete to hide the key")
+	}
+	if m.values["net"] != 3 {
+		t.Errorf("expected nothing to be written before Commit")
+	}
+	if err := tx.Commit(); err != nil || m.values["net"] != 4 {
+		t.Errorf("expected Commit to write 4, got %d (%v)", m.values["net"], err)
+	}
+
+	// a key that gets overwritten, even with the same value, is a conflict
+	tx = Begin(m.read, m.apply)
+	tx.Get("net")
+	tx.Insert("total", 100)
+	m.insert("net", 4)
+	if err := tx.Commit(); err != ErrConflict {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	if _, ok := m.values["total"]; ok {
+		t.Errorf("expected a conflicting transaction to write nothing")
+	}
+
+	// so is a key that didn't exist when it was read, but does now
+	tx = Begin(m.read, m.apply)
+	tx.Get("fees")
+	m.insert("fees", -10)
+	if err := tx.Commit(); err != ErrConflict {
+		t.Errorf("expected ErrConflict for a key that appeared, got %v", err)
+	}
+}
+
+func TestTxnByValue(t *testing.T) {
+	m := newVersionedMap()
+	m.insert("net", 3)
+	read := func(key string) (bool, int) {
+		found, val, _ := m.read(key)
+		return found, val
+	}
+
+	// without versions, overwriting a key with the same value goes unnoticed
+	tx := BeginByValue(read, m.apply)
+	tx.Get("net")
+	tx.Insert("total", 100)
+	m.insert("net", 3)
+	if err := tx.Commit(); err != nil || m.values["total"] != 100 {
+		t.Errorf("expected Commit to write 100, got %d (%v)", m.values["total"], err)
+	}
+
+	// but a different value is a conflict, and so is a key that appeared
+	for _, key := range []string{"net", "fees"} {
+		tx = BeginByValue(read, m.apply)
+		tx.Get(key)
+		tx.Insert("total", 200)
+		m.insert(key, 4)
+		if err := tx.Commit(); err != ErrConflict {
+			t.Errorf("expected ErrConflict after changing %s, got %v", key, err)
+		}
+	}
+	if m.values["total"] != 100 {
+		t.Errorf("expected conflicting transactions to write nothing")
+	}
+}
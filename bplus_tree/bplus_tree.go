@@ -0,0 +1,364 @@
+package bplus_tree
+
+import (
+	"sort"
+	"strings"
+)
+
+// A B+tree keeps keys sorted in wide nodes: internal nodes only hold separator keys for finding the right
+// child, and all keys and values live in the leaves, which are linked left to right for ordered scans.
+// Every operation is O(log n) comparisons of whole keys, so unlike the tries it doesn't care how many
+// characters or dots a key has.
+
+// DefaultFanout is the maximum number of children of an internal node (and keys in a leaf) used by New
+const DefaultFanout = 64
+
+type node[T any] struct {
+	leaf bool
+	// internal nodes: children[i] holds the keys k with keys[i-1] <= k < keys[i]
+	// leaves: the keys stored in this leaf, with values[i] belonging to keys[i]
+	keys     []string
+	children []*node[T]
+	values   []T
+	// next leaf to the right
+	next *node[T]
+}
+
+type Tree[T any] struct {
+	root    *node[T]
+	fanout  int
+	numKeys int
+}
+
+func New[T any]() *Tree[T] {
+	return NewWithFanout[T](DefaultFanout)
+}
+
+// NewWithFanout returns a Tree whose nodes hold up to fanout children (internal nodes) or keys (leaves).
+// It's at least 3, or nodes couldn't split.
+func NewWithFanout[T any](fanout int) *Tree[T] {
+	return &Tree[T]{root: &node[T]{leaf: true}, fanout: max(fanout, 3)}
+}
+
+// Len returns the number of keys
+func (t *Tree[T]) Len() int {
+	return t.numKeys
+}
+
+// childIndex returns which child of an internal node key belongs in
+func (n *node[T]) childIndex(key string) int {
+	return sort.Search(len(n.keys), func(i int) bool { return n.keys[i] > key })
+}
+
+// findLeaf returns the leaf that key belongs in
+func (t *Tree[T]) findLeaf(key string) *node[T] {
+	n := t.root
+	for !n.leaf {
+		n = n.children[n.childIndex(key)]
+	}
+	return n
+}
+
+// Search returns whether key exists, and its value
+func (t *Tree[T]) Search(key string) (bool, T) {
+	leaf := t.findLeaf(key)
+	i := sort.SearchStrings(leaf.keys, key)
+	if i < len(leaf.keys) && leaf.keys[i] == key {
+		return true, leaf.values[i]
+	}
+	var zero T
+	return false, zero
+}
+
+func (t *Tree[T]) Insert(key string, val T) {
+	sep, right := t.insert(t.root, key, val)
+	if right != nil {
+		// the root split, so the tree grows a level
+		t.root = &node[T]{keys: []string{sep}, children: []*node[T]{t.root, right}}
+	}
+}
+
+// insert adds key below n. If n had to split, it returns the new right half and the first key in it.
+func (t *Tree[T]) insert(n *node[T], key string, val T) (string, *node[T]) {
+	if n.leaf {
+		i := sort.SearchStrings(n.keys, key)
+		if i < len(n.keys) && n.keys[i] == key {
+			n.values[i] = val
+			return "", nil
+		}
+		n.keys = insertAt(n.keys, i, key)
+		n.values = insertAt(n.values, i, val)
+		t.numKeys++
+		if len(n.keys) <= t.fanout {
+			return "", nil
+		}
+		// split in half, the right half's first key goes up to the parent
+		mid := len(n.keys) / 2
+		right := &node[T]{
+			leaf:   true,
+			keys:   append([]string(nil), n.keys[mid:]...),
+			values: append([]T(nil), n.values[mid:]...),
+			next:   n.next,
+		}
+		n.keys = n.keys[:mid:mid]
+		n.values = n.values[:mid:mid]
+		n.next = right
+		return right.keys[0], right
+	}
+
+	i := n.childIndex(key)
+	sep, right := t.insert(n.children[i], key, val)
+	if right == nil {
+		return "", nil
+	}
+	n.keys = insertAt(n.keys, i, sep)
+	n.children = insertAt(n.children, i+1, right)
+	if len(n.children) <= t.fanout {
+		return "", nil
+	}
+	// split in half, the middle key moves up to the parent instead of staying in either half
+	mid := len(n.keys) / 2
+	sep = n.keys[mid]
+	newRight := &node[T]{
+		keys:     append([]string(nil), n.keys[mid+1:]...),
+		children: append([]*node[T](nil), n.children[mid+1:]...),
+	}
+	n.keys = n.keys[:mid:mid]
+	n.children = n.children[: mid+1 : mid+1]
+	return sep, newRight
+}
+
+func insertAt[E any](s []E, i int, e E) []E {
+	var zero E
+	s = append(s, zero)
+	copy(s[i+1:], s[i:])
+	s[i] = e
+	return s
+}
+
+func removeAt[E any](s []E, i int) []E {
+	copy(s[i:], s[i+1:])
+	var zero E
+	s[len(s)-1] = zero
+	return s[:len(s)-1]
+}
+
+// Delete removes key from the tree and reports whether it was there.
+// Nodes that get less than half full borrow from or merge with a sibling, so the tree stays balanced.
+func (t *Tree[T]) Delete(key string) bool {
+	if !t.delete(t.root, key) {
+		return false
+	}
+	t.numKeys--
+	// the root lost its last separator, so its only child becomes the root
+	if !t.root.leaf && len(t.root.children) == 1 {
+		t.root = t.root.children[0]
+	}
+	return true
+}
+
+func (t *Tree[T]) minLeafKeys() int {
+	return t.fanout / 2
+}
+
+func (t *Tree[T]) minChildren() int {
+	return (t.fanout + 1) / 2
+}
+
+func (t *Tree[T]) delete(n *node[T], key string) bool {
+	if n.leaf {
+		i := sort.SearchStrings(n.keys, key)
+		if i == len(n.keys) || n.keys[i] != key {
+			return false
+		}
+		n.keys = removeAt(n.keys, i)
+		n.values = removeAt(n.values, i)
+		return true
+	}
+	i := n.childIndex(key)
+	if !t.delete(n.children[i], key) {
+		return false
+	}
+	t.rebalance(n, i)
+	return true
+}
+
+// rebalance fixes up n.children[i] if a delete left it less than half full
+func (t *Tree[T]) rebalance(n *node[T], i int) {
+	child := n.children[i]
+	var left, right *node[T]
+	if i > 0 {
+		left = n.children[i-1]
+	}
+	if i+1 < len(n.children) {
+		right = n.children[i+1]
+	}
+
+	if child.leaf {
+		min := t.minLeafKeys()
+		switch {
+		case len(child.keys) >= min:
+		case left != nil && len(left.keys) > min:
+			// take the left sibling's last key
+			last := len(left.keys) - 1
+			child.keys = insertAt(child.keys, 0, left.keys[last])
+			child.values = insertAt(child.values, 0, left.values[last])
+			left.keys = removeAt(left.keys, last)
+			left.values = removeAt(left.values, last)
+			n.keys[i-1] = child.keys[0]
+		case right != nil && len(right.keys) > min:
+			// take the right sibling's first key
+			child.keys = append(child.keys, right.keys[0])
+			child.values = append(child.values, right.values[0])
+			right.keys = removeAt(right.keys, 0)
+			right.values = removeAt(right.values, 0)
+			n.keys[i] = right.keys[0]
+		case left != nil:
+			mergeLeaves(left, child)
+			n.keys = removeAt(n.keys, i-1)
+			n.children = removeAt(n.children, i)
+		case right != nil:
+			mergeLeaves(child, right)
+			n.keys = removeAt(n.keys, i)
+			n.children = removeAt(n.children, i+1)
+		}
+		return
+	}
+
+	min := t.minChildren()
+	switch {
+	case len(child.children) >= min:
+	case left != nil && len(left.children) > min:
+		// rotate through the parent: its separator comes down, the left sibling's last key goes up
+		last := len(left.keys) - 1
+		child.keys = insertAt(child.keys, 0, n.keys[i-1])
+		child.children = insertAt(child.children, 0, left.children[last+1])
+		n.keys[i-1] = left.keys[last]
+		left.keys = removeAt(left.keys, last)
+		left.children = removeAt(left.children, last+1)
+	case right != nil && len(right.children) > min:
+		child.keys = append(child.keys, n.keys[i])
+		child.children = append(child.children, right.children[0])
+		n.keys[i] = right.keys[0]
+		right.keys = removeAt(right.keys, 0)
+		right.children = removeAt(right.children, 0)
+	case left != nil:
+		mergeInternal(left, child, n.keys[i-1])
+		n.keys = removeAt(n.keys, i-1)
+		n.children = removeAt(n.children, i)
+	case right != nil:
+		mergeInternal(child, right, n.keys[i])
+		n.keys = removeAt(n.keys, i)
+		n.children = removeAt(n.children, i+1)
+	}
+}
+
+// mergeLeaves moves everything in right into left
+func mergeLeaves[T any](left, right *node[T]) {
+	left.keys = append(left.keys, right.keys...)
+	left.values = append(left.values, right.values...)
+	left.next = right.next
+}
+
+// mergeInternal moves everything in right into left, with the parent's separator between them
+func mergeInternal[T any](left, right *node[T], sep string) {
+	left.keys = append(append(left.keys, sep), right.keys...)
+	left.children = append(left.children, right.children...)
+}
+
+// KeyValue is a single key and its value, used wherever results need a stable order
+type KeyValue[T any] struct {
+	Key   string
+	Value T
+}
+
+// SearchPrefix returns all keys with the given prefix, mapped to their values
+func (t *Tree[T]) SearchPrefix(prefix string) map[string]T {
+	results := make(map[string]T)
+	if len(prefix) == 0 {
+		return results
+	}
+	t.SearchPrefixFunc(prefix, func(key string, val T) bool {
+		results[key] = val
+		return true
+	})
+	return results
+}
+
+// SearchPrefixFunc calls fn for every key with the given prefix in lexicographic order, stopping early if fn returns false
+func (t *Tree[T]) SearchPrefixFunc(prefix string, fn func(key string, val T) bool) {
+	// keys with the prefix are all next to each other, starting at the first key >= prefix
+	for c := t.Seek(prefix); c.Valid() && strings.HasPrefix(c.Key(), prefix); c.Next() {
+		if !fn(c.Key(), c.Value()) {
+			return
+		}
+	}
+}
+
+// Each calls fn for every key in lexicographic order, stopping early if fn returns false
+func (t *Tree[T]) Each(fn func(key string, val T) bool) {
+	for c := t.Seek(""); c.Valid(); c.Next() {
+		if !fn(c.Key(), c.Value()) {
+			return
+		}
+	}
+}
+
+// Range returns all keys k with startKey <= k < endKey, in lexicographic order.
+// An empty endKey means "no upper bound".
+func (t *Tree[T]) Range(startKey, endKey string) []KeyValue[T] {
+	var results []KeyValue[T]
+	for c := t.Seek(startKey); c.Valid(); c.Next() {
+		if endKey != "" && c.Key() >= endKey {
+			break
+		}
+		results = append(results, KeyValue[T]{Key: c.Key(), Value: c.Value()})
+	}
+	return results
+}
+
+// Cursor walks the tree's keys in lexicographic order by following the linked leaves.
+// The tree must not be modified while a Cursor is in use.
+type Cursor[T any] struct {
+	leaf *node[T]
+	pos  int
+}
+
+// Seek returns a Cursor positioned at the first key >= key
+func (t *Tree[T]) Seek(key string) *Cursor[T] {
+	leaf := t.findLeaf(key)
+	c := &Cursor[T]{leaf: leaf, pos: sort.SearchStrings(leaf.keys, key)}
+	c.skipEmpty()
+	return c
+}
+
+// skipEmpty moves on to the next leaf if the cursor ran off the end of this one
+func (c *Cursor[T]) skipEmpty() {
+	for c.leaf != nil && c.pos >= len(c.leaf.keys) {
+		c.leaf = c.leaf.next
+		c.pos = 0
+	}
+}
+
+// Valid reports whether the cursor points at a key (false once it has run off the end)
+func (c *Cursor[T]) Valid() bool {
+	return c.leaf != nil
+}
+
+// Next moves the cursor to the next key
+func (c *Cursor[T]) Next() {
+	if c.Valid() {
+		c.pos++
+		c.skipEmpty()
+	}
+}
+
+// Key returns the key the cursor points at
+func (c *Cursor[T]) Key() string {
+	return c.leaf.keys[c.pos]
+}
+
+// Value returns the value for the key the cursor points at
+func (c *Cursor[T]) Value() T {
+	return c.leaf.values[c.pos]
+}
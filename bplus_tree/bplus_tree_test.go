@@ -0,0 +1,184 @@
+package bplus_tree
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// checkTree makes sure every node is sorted, within its bounds and (except the root) at least half full,
+// and that all leaves are at the same depth
+func checkTree[T any](t *testing.T, tree *Tree[T]) {
+	t.Helper()
+	leafDepth := -1
+	var check func(n *node[T], depth int, lo, hi *string)
+	check = func(n *node[T], depth int, lo, hi *string) {
+		if !sort.StringsAreSorted(n.keys) {
+			t.Fatalf("unsorted node keys %v", n.keys)
+		}
+		for _, key := range n.keys {
+			if (lo != nil && key < *lo) || (hi != nil && key >= *hi) {
+				t.Fatalf("key %q out of bounds", key)
+			}
+		}
+		if n.leaf {
+			if leafDepth == -1 {
+				leafDepth = depth
+			} else if depth != leafDepth {
+				t.Fatalf("leaves at different depths %d and %d", leafDepth, depth)
+			}
+			if n != tree.root && len(n.keys) < tree.minLeafKeys() {
+				t.Fatalf("leaf with only %d keys", len(n.keys))
+			}
+			return
+		}
+		if len(n.children) != len(n.keys)+1 {
+			t.Fatalf("internal node with %d keys and %d children", len(n.keys), len(n.children))
+		}
+		if n != tree.root && len(n.children) < tree.minChildren() {
+			t.Fatalf("internal node with only %d children", len(n.children))
+		}
+		for i, child := range n.children {
+			childLo, childHi := lo, hi
+			if i > 0 {
+				childLo = &n.keys[i-1]
+			}
+			if i < len(n.keys) {
+				childHi = &n.keys[i]
+			}
+			check(child, depth+1, childLo, childHi)
+		}
+	}
+	check(tree.root, 0, nil, nil)
+}
+
+func TestInsertSearchDelete(t *testing.T) {
+	for _, fanout := range []int{3, 4, 5, DefaultFanout} {
+		tree := NewWithFanout[int](fanout)
+		expected := make(map[string]int)
+		r := rand.New(rand.NewSource(int64(fanout)))
+		for i := 0; i < 2000; i++ {
+			key := fmt.Sprintf("key.%d", r.Intn(1000))
+			if r.Intn(3) == 0 {
+				_, exists := expected[key]
+				if tree.Delete(key) != exists {
+					t.Fatalf("fanout %d: expected Delete(%q) to report %v", fanout, key, exists)
+				}
+				delete(expected, key)
+			} else {
+				tree.Insert(key, i)
+				expected[key] = i
+			}
+		}
+		checkTree(t, tree)
+
+		if tree.Len() != len(expected) {
+			t.Errorf("fanout %d: expected %d keys, got %d", fanout, len(expected), tree.Len())
+		}
+		for key, val := range expected {
+			if found, got := tree.Search(key); !found || got != val {
+				t.Errorf("fanout %d: expected %q to be %d, got %v %d", fanout, key, val, found, got)
+			}
+		}
+
+		// iteration visits exactly the expected keys, in order
+		var keys []string
+		tree.Each(func(key string, val int) bool {
+			keys = append(keys, key)
+			return true
+		})
+		var expectedKeys []string
+		for key := range expected {
+			expectedKeys = append(expectedKeys, key)
+		}
+		sort.Strings(expectedKeys)
+		if !reflect.DeepEqual(keys, expectedKeys) {
+			t.Errorf("fanout %d: expected keys %v, got %v", fanout, expectedKeys, keys)
+		}
+
+		// and everything can be deleted again
+		for key := range expected {
+			tree.Delete(key)
+		}
+		checkTree(t, tree)
+		if tree.Len() != 0 || tree.Seek("").Valid() {
+			t.Errorf("fanout %d: expected an empty tree", fanout)
+		}
+	}
+}
+
+func TestFanout(t *testing.T) {
+	tree := NewWithFanout[int](4)
+	for i := 0; i < 4; i++ {
+		tree.Insert(fmt.Sprintf("key%d", i), i)
+	}
+	if !tree.root.leaf || len(tree.root.keys) != 4 {
+		t.Errorf("expected a leaf to hold 4 keys before it splits, got %d", len(tree.root.keys))
+	}
+	tree.Insert("key4", 4)
+	if tree.root.leaf || len(tree.root.children) != 2 {
+		t.Errorf("expected the fifth key to split the leaf")
+	}
+}
+
+func TestRangeAndPrefix(t *testing.T) {
+	tree := NewWithFanout[int](3)
+	for i, key := range []string{"aa", "ab", "abc", "b", "ba", "c", "profits", "profits.revenue", "profits.loss", "profitz"} {
+		tree.Insert(key, i)
+	}
+
+	results := tree.Range("ab", "ba")
+	expected := []KeyValue[int]{{"ab", 1}, {"abc", 2}, {"b", 3}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected Range to return %v, got %v", expected, results)
+	}
+
+	c := tree.Seek("abd")
+	if !c.Valid() || c.Key() != "b" {
+		t.Errorf("expected Seek(abd) to land on b")
+	}
+	if c := tree.Seek("zzz"); c.Valid() {
+		t.Errorf("expected Seek past the last key to be invalid, got %s", c.Key())
+	}
+
+	prefixResults := tree.SearchPrefix("profits")
+	expectedPrefix := map[string]int{"profits": 6, "profits.revenue": 7, "profits.loss": 8}
+	if !reflect.DeepEqual(prefixResults, expectedPrefix) {
+		t.Errorf("expected %v, got %v", expectedPrefix, prefixResults)
+	}
+	if results := tree.SearchPrefix("nope"); len(results) != 0 {
+		t.Errorf("expected no results, got %v", results)
+	}
+}
+
+func TestStats(t *testing.T) {
+	tree := NewWithFanout[int](4)
+	for i := 0; i < 100; i++ {
+		tree.Insert(fmt.Sprintf("key%03d", i), i)
+	}
+	stats := tree.Stats()
+	if stats.Keys != 100 || stats.StringBytes < 100*len("key000") {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	// all keys are in leaves, which are all at the same depth
+	if stats.MaxDepth < 3 || stats.AvgDepth != float64(stats.MaxDepth) {
+		t.Errorf("expected every key at the same depth, got %d and %v", stats.MaxDepth, stats.AvgDepth)
+	}
+	internal := 0
+	for fanout, n := range stats.Fanout {
+		if fanout > 4 || (fanout > 0 && fanout < 2) {
+			t.Errorf("expected internal nodes to have 2 to 4 children, got %v", stats.Fanout)
+		}
+		if fanout > 0 {
+			internal += n
+		}
+	}
+	if stats.ValuelessNodes != internal-1 || stats.Nodes != internal+stats.Fanout[0] {
+		t.Errorf("expected every internal node but the root to be valueless, got %+v", stats)
+	}
+	if stats.NodeBytes <= 0 || stats.ChildrenBytes <= 0 || stats.ValueBytes < 100*8 {
+		t.Errorf("unexpected bytes %+v", stats)
+	}
+}
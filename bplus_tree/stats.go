@@ -0,0 +1,40 @@
+package bplus_tree
+
+import (
+	"unsafe"
+
+	"github.com/groovemonkey/trie-keys-experiment/stats"
+)
+
+// Stats walks the whole tree and returns its Stats.
+// Every key is in a leaf, so all keys are at the same depth, leaves have no children and every internal node
+// below the root is valueless. Node bytes include the nodes' key slices, and string bytes count separator
+// keys in internal nodes as well as the keys in the leaves.
+func (t *Tree[T]) Stats() stats.Stats {
+	var zero T
+	st := stats.New()
+	var visit func(n *node[T], depth int)
+	visit = func(n *node[T], depth int) {
+		st.Nodes++
+		st.NodeBytes += int(unsafe.Sizeof(*n)) + cap(n.keys)*int(unsafe.Sizeof(""))
+		for _, key := range n.keys {
+			st.StringBytes += len(key)
+		}
+		st.Fanout[len(n.children)]++
+		if n.leaf {
+			st.AddKeys(depth, len(n.keys))
+			st.ValueBytes += cap(n.values) * int(unsafe.Sizeof(zero))
+			return
+		}
+		if depth > 0 {
+			st.ValuelessNodes++
+		}
+		st.ChildrenBytes += cap(n.children) * int(unsafe.Sizeof(n))
+		for _, child := range n.children {
+			visit(child, depth+1)
+		}
+	}
+	visit(t.root, 0)
+	st.Finish()
+	return st
+}
@@ -0,0 +1,318 @@
+package burst_trie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/maphash"
+)
+
+// A burst trie (the idea behind the HAT-trie) keeps keys in small hash containers instead of one node per
+// character. A container holds the remaining suffixes of all keys below its position in the trie, packed
+// into a few byte slices so a lookup scans contiguous memory instead of chasing pointers.
+// When a container gets too big it "bursts" into a trie node with one child container per next byte.
+//
+// Point lookups walk a few trie nodes and then hash into one container, close to what a map does.
+// Prefix searches walk down to the prefix and only look at the containers below it,
+// instead of scanning every key like mapkeys.Store does.
+
+// DefaultBurstThreshold is the number of keys a container holds before it bursts
+const DefaultBurstThreshold = 256
+
+// numBuckets is the number of buckets in a container, so with the default threshold a bucket holds about 8 keys
+const numBuckets = 32
+
+type node[T any] struct {
+	// a node is either a container (before it bursts) or a trie node with one child per byte (after)
+	container *container[T]
+	children  *[256]*node[T]
+	// value for the key that ends exactly at this trie node
+	value T
+	// avoid mistaking initialized zero values for intentional zero values
+	hasValue bool
+}
+
+type Trie[T any] struct {
+	root      *node[T]
+	threshold int
+	numKeys   int
+}
+
+func New[T any]() *Trie[T] {
+	return NewWithThreshold[T](DefaultBurstThreshold)
+}
+
+// NewWithThreshold returns a Trie whose containers burst once they hold more than threshold keys.
+// Smaller thresholds make it behave more like a trie, bigger ones more like a hash map.
+func NewWithThreshold[T any](threshold int) *Trie[T] {
+	return &Trie[T]{root: newContainerNode[T](), threshold: max(threshold, 1)}
+}
+
+func newContainerNode[T any]() *node[T] {
+	return &node[T]{container: &container[T]{}}
+}
+
+// Len returns the number of keys
+func (t *Trie[T]) Len() int {
+	return t.numKeys
+}
+
+func (t *Trie[T]) Insert(key string, val T) {
+	n, rest := t.root, key
+	for n.children != nil {
+		if rest == "" {
+			if !n.hasValue {
+				t.numKeys++
+			}
+			n.value = val
+			n.hasValue = true
+			return
+		}
+		child := n.children[rest[0]]
+		if child == nil {
+			child = newContainerNode[T]()
+			n.children[rest[0]] = child
+		}
+		n, rest = child, rest[1:]
+	}
+	if n.container.insert(rest, val) {
+		t.numKeys++
+	}
+	if n.container.count > t.threshold {
+		n.burst()
+	}
+}
+
+// burst turns a container node into a trie node, moving each suffix into the child container for its first byte
+func (n *node[T]) burst() {
+	c := n.container
+	n.container = nil
+	n.children = new([256]*node[T])
+	c.each(func(suffix []byte, val T) bool {
+		if len(suffix) == 0 {
+			n.value = val
+			n.hasValue = true
+			return true
+		}
+		child := n.children[suffix[0]]
+		if child == nil {
+			child = newContainerNode[T]()
+			n.children[suffix[0]] = child
+		}
+		child.container.insert(string(suffix[1:]), val)
+		return true
+	})
+}
+
+// find walks the trie nodes for key and returns the node it ends in, and what's left of the key
+// (empty if it ended on a trie node, the suffix to look up if it ended on a container)
+func (t *Trie[T]) find(key string) (*node[T], string) {
+	n, rest := t.root, key
+	for n.children != nil && rest != "" {
+		n = n.children[rest[0]]
+		if n == nil {
+			return nil, ""
+		}
+		rest = rest[1:]
+	}
+	return n, rest
+}
+
+// Search returns whether key exists, and its value
+func (t *Trie[T]) Search(key string) (bool, T) {
+	var zero T
+	n, rest := t.find(key)
+	switch {
+	case n == nil:
+		return false, zero
+	case n.container != nil:
+		return n.container.search(rest)
+	case n.hasValue:
+		return true, n.value
+	}
+	return false, zero
+}
+
+// Delete removes key and reports whether it was there.
+// Trie nodes are never merged back into containers.
+func (t *Trie[T]) Delete(key string) bool {
+	n, rest := t.find(key)
+	var deleted bool
+	switch {
+	case n == nil:
+	case n.container != nil:
+		deleted = n.container.delete(rest)
+	case n.hasValue:
+		var zero T
+		n.value = zero
+		n.hasValue = false
+		deleted = true
+	}
+	if deleted {
+		t.numKeys--
+	}
+	return deleted
+}
+
+// SearchPrefix returns all keys with the given prefix, mapped to their values
+func (t *Trie[T]) SearchPrefix(prefix string) map[string]T {
+	results := make(map[string]T)
+	if len(prefix) == 0 {
+		return results
+	}
+	n, rest := t.find(prefix)
+	if n == nil {
+		return results
+	}
+	// the bytes of prefix that led to n
+	key := []byte(prefix[:len(prefix)-len(rest)])
+	if n.container != nil {
+		// only the suffixes in this container that start with the rest of the prefix
+		restBytes := []byte(rest)
+		n.container.each(func(suffix []byte, val T) bool {
+			if bytes.HasPrefix(suffix, restBytes) {
+				results[string(key)+string(suffix)] = val
+			}
+			return true
+		})
+		return results
+	}
+	n.each(key, func(key []byte, val T) bool {
+		results[string(key)] = val
+		return true
+	})
+	return results
+}
+
+// Each calls fn for every key, stopping early if fn returns false.
+// Trie nodes are visited in byte order, but the keys in a container come out in hash order, so the order is arbitrary.
+func (t *Trie[T]) Each(fn func(key string, val T) bool) {
+	t.root.each(nil, func(key []byte, val T) bool {
+		return fn(string(key), val)
+	})
+}
+
+// each calls fn for every key in n's subtree, and reports false if fn stopped it by returning false.
+// key holds the bytes that led to n and is reused between calls.
+func (n *node[T]) each(key []byte, fn func(key []byte, val T) bool) ([]byte, bool) {
+	if n.container != nil {
+		depth := len(key)
+		ok := n.container.each(func(suffix []byte, val T) bool {
+			key = append(key[:depth], suffix...)
+			return fn(key, val)
+		})
+		return key[:depth], ok
+	}
+	if n.hasValue && !fn(key, n.value) {
+		return key, false
+	}
+	for b, child := range n.children {
+		if child != nil {
+			var ok bool
+			key, ok = child.each(append(key, byte(b)), fn)
+			key = key[:len(key)-1]
+			if !ok {
+				return key, false
+			}
+		}
+	}
+	return key, true
+}
+
+// container is an "array hash": each bucket is one byte slice with its entries packed back to back as
+// uvarint(len(suffix)), suffix, uvarint(slot), where slot indexes into values
+type container[T any] struct {
+	buckets [numBuckets][]byte
+	values  []T
+	// slots in values freed by deletes
+	free  []uint64
+	count int
+}
+
+// the same hash Go maps use, which is a lot faster than a byte-at-a-time loop on long keys
+var hashSeed = maphash.MakeSeed()
+
+func bucketFor(suffix string) int {
+	return int(maphash.String(hashSeed, suffix) % numBuckets)
+}
+
+// find returns the slot for suffix and where its entry starts and ends in the bucket, or ok=false
+func (c *container[T]) find(bucket []byte, suffix string) (slot uint64, start, end int, ok bool) {
+	for pos := 0; pos < len(bucket); {
+		start = pos
+		length, n := binary.Uvarint(bucket[pos:])
+		pos += n
+		entry := bucket[pos : pos+int(length)]
+		pos += int(length)
+		slot, n = binary.Uvarint(bucket[pos:])
+		pos += n
+		if string(entry) == suffix {
+			return slot, start, pos, true
+		}
+	}
+	return 0, 0, 0, false
+}
+
+func (c *container[T]) search(suffix string) (bool, T) {
+	var zero T
+	slot, _, _, ok := c.find(c.buckets[bucketFor(suffix)], suffix)
+	if !ok {
+		return false, zero
+	}
+	return true, c.values[slot]
+}
+
+// insert sets suffix to val and reports whether it's a new key
+func (c *container[T]) insert(suffix string, val T) bool {
+	b := bucketFor(suffix)
+	if slot, _, _, ok := c.find(c.buckets[b], suffix); ok {
+		c.values[slot] = val
+		return false
+	}
+	var slot uint64
+	if len(c.free) > 0 {
+		slot = c.free[len(c.free)-1]
+		c.free = c.free[:len(c.free)-1]
+		c.values[slot] = val
+	} else {
+		slot = uint64(len(c.values))
+		c.values = append(c.values, val)
+	}
+	bucket := binary.AppendUvarint(c.buckets[b], uint64(len(suffix)))
+	bucket = append(bucket, suffix...)
+	c.buckets[b] = binary.AppendUvarint(bucket, slot)
+	c.count++
+	return true
+}
+
+func (c *container[T]) delete(suffix string) bool {
+	b := bucketFor(suffix)
+	slot, start, end, ok := c.find(c.buckets[b], suffix)
+	if !ok {
+		return false
+	}
+	c.buckets[b] = append(c.buckets[b][:start], c.buckets[b][end:]...)
+	var zero T
+	c.values[slot] = zero
+	c.free = append(c.free, slot)
+	c.count--
+	return true
+}
+
+// each calls fn for every suffix in the container, in no particular order, and reports false if fn stopped it
+// by returning false. suffix points into the bucket, so fn must copy it if it keeps it.
+func (c *container[T]) each(fn func(suffix []byte, val T) bool) bool {
+	for _, bucket := range c.buckets {
+		for pos := 0; pos < len(bucket); {
+			length, n := binary.Uvarint(bucket[pos:])
+			pos += n
+			suffix := bucket[pos : pos+int(length)]
+			pos += int(length)
+			slot, n := binary.Uvarint(bucket[pos:])
+			pos += n
+			if !fn(suffix, c.values[slot]) {
+				return false
+			}
+		}
+	}
+	return true
+}
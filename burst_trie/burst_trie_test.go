@@ -0,0 +1,132 @@
+package burst_trie
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestInsertAndSearch(t *testing.T) {
+	// a tiny threshold, so most of these keys end up in burst nodes
+	for _, trie := range []*Trie[int]{New[int](), NewWithThreshold[int](2)} {
+		keys := []string{"profits", "profits.revenue", "profits.revenue.net", "profit", "p", "", "héllo", "hello"}
+		for i, key := range keys {
+			trie.Insert(key, i)
+		}
+		trie.Insert("profit", 100)
+
+		if trie.Len() != len(keys) {
+			t.Errorf("expected %d keys, got %d", len(keys), trie.Len())
+		}
+		for i, key := range keys {
+			expected := i
+			if key == "profit" {
+				expected = 100
+			}
+			if found, val := trie.Search(key); !found || val != expected {
+				t.Errorf("expected %q to be %d, got %v %d", key, expected, found, val)
+			}
+		}
+		for _, key := range []string{"prof", "profits.", "x", "hé"} {
+			if found, _ := trie.Search(key); found {
+				t.Errorf("expected %q not to be found", key)
+			}
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	trie := NewWithThreshold[int](4)
+	for i := 0; i < 100; i++ {
+		trie.Insert(fmt.Sprintf("key.%d", i), i)
+	}
+	for i := 0; i < 100; i += 2 {
+		if !trie.Delete(fmt.Sprintf("key.%d", i)) {
+			t.Errorf("expected key.%d to be deleted", i)
+		}
+	}
+	if trie.Delete("key.0") {
+		t.Errorf("expected deleting a missing key to report false")
+	}
+	if trie.Len() != 50 {
+		t.Errorf("expected 50 keys left, got %d", trie.Len())
+	}
+	for i := 0; i < 100; i++ {
+		found, val := trie.Search(fmt.Sprintf("key.%d", i))
+		if found != (i%2 == 1) || (found && val != i) {
+			t.Errorf("unexpected result for key.%d: %v %d", i, found, val)
+		}
+	}
+	// deleted slots get reused
+	trie.Insert("key.0", -1)
+	if found, val := trie.Search("key.0"); !found || val != -1 {
+		t.Errorf("expected key.0 to be back, got %v %d", found, val)
+	}
+}
+
+func TestSearchPrefix(t *testing.T) {
+	for _, threshold := range []int{1, 3, DefaultBurstThreshold} {
+		trie := NewWithThreshold[int](threshold)
+		for i, key := range []string{"profits", "profits.revenue", "profits.revenue.net", "profits.loss", "profit", "pro", "losses"} {
+			trie.Insert(key, i)
+		}
+
+		results := trie.SearchPrefix("profits")
+		if len(results) != 4 || results["profits.loss"] != 3 || results["profits"] != 0 {
+			t.Errorf("threshold %d: expected 4 results, got %v", threshold, results)
+		}
+		if results := trie.SearchPrefix("profits.revenue.net"); len(results) != 1 {
+			t.Errorf("threshold %d: expected the key itself, got %v", threshold, results)
+		}
+		if results := trie.SearchPrefix("nope"); len(results) != 0 {
+			t.Errorf("threshold %d: expected no results, got %v", threshold, results)
+		}
+	}
+}
+
+func TestEach(t *testing.T) {
+	for _, trie := range []*Trie[int]{New[int](), NewWithThreshold[int](2)} {
+		expected := make(map[string]int)
+		for i, key := range []string{"profits", "profits.revenue", "profits.revenue.net", "profit", "p", "", "héllo"} {
+			trie.Insert(key, i)
+			expected[key] = i
+		}
+		got := make(map[string]int)
+		trie.Each(func(key string, val int) bool {
+			got[key] = val
+			return true
+		})
+		if fmt.Sprint(got) != fmt.Sprint(expected) {
+			t.Errorf("expected %v, got %v", expected, got)
+		}
+		visited := 0
+		trie.Each(func(key string, val int) bool {
+			visited++
+			return visited < 3
+		})
+		if visited != 3 {
+			t.Errorf("expected Each to stop after 3 keys, visited %d", visited)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	trie := NewWithThreshold[int](2)
+	// b bursts the root into a and b, then ad bursts a into b and d
+	for _, key := range []string{"ab", "abc", "b", "ad", "c"} {
+		trie.Insert(key, 1)
+	}
+	stats := trie.Stats()
+	if stats.Keys != 5 || stats.Nodes != 6 || stats.ValuelessNodes != 1 {
+		t.Errorf("expected 5 keys in 6 nodes with 1 valueless one, got %+v", stats)
+	}
+	// b and c are in containers at depth 1, ab, abc and ad in containers at depth 2
+	if stats.MaxDepth != 2 || stats.AvgDepth != 8.0/5 {
+		t.Errorf("expected depths of 2 and 8/5, got %d and %v", stats.MaxDepth, stats.AvgDepth)
+	}
+	if stats.Fanout[3] != 1 || stats.Fanout[2] != 1 || stats.Fanout[0] != 4 {
+		t.Errorf("expected a root with 3 children, one node with 2 and 4 containers, got %v", stats.Fanout)
+	}
+	if stats.ChildrenBytes != 2*256*8 || stats.StringBytes <= 0 || stats.ValueBytes <= 0 {
+		t.Errorf("unexpected bytes %+v", stats)
+	}
+}
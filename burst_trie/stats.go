@@ -0,0 +1,53 @@
+package burst_trie
+
+import (
+	"unsafe"
+
+	"github.com/groovemonkey/trie-keys-experiment/stats"
+)
+
+// Stats walks the whole trie and returns its Stats.
+// Both trie nodes and containers count as nodes. A key's depth is the depth of the node it ends up in,
+// containers have no children, and only empty containers count as valueless.
+// Children bytes are the trie nodes' child arrays, and string bytes the containers' packed buckets.
+func (t *Trie[T]) Stats() stats.Stats {
+	var zero T
+	valueSize := int(unsafe.Sizeof(zero))
+	st := stats.New()
+	var visit func(n *node[T], depth int)
+	visit = func(n *node[T], depth int) {
+		st.Nodes++
+		st.NodeBytes += int(unsafe.Sizeof(*n)) - valueSize
+		st.ValueBytes += valueSize
+		keys := 0
+		if n.hasValue {
+			keys = 1
+		}
+		if c := n.container; c != nil {
+			keys += c.count
+			st.Fanout[0]++
+			st.NodeBytes += int(unsafe.Sizeof(*c)) + cap(c.free)*int(unsafe.Sizeof(uint64(0)))
+			st.ValueBytes += cap(c.values) * valueSize
+			for _, bucket := range c.buckets {
+				st.StringBytes += cap(bucket)
+			}
+		} else {
+			st.ChildrenBytes += int(unsafe.Sizeof(*n.children))
+			numChildren := 0
+			for _, child := range n.children {
+				if child != nil {
+					numChildren++
+					visit(child, depth+1)
+				}
+			}
+			st.Fanout[numChildren]++
+		}
+		st.AddKeys(depth, keys)
+		if keys == 0 && depth > 0 {
+			st.ValuelessNodes++
+		}
+	}
+	visit(t.root, 0)
+	st.Finish()
+	return st
+}
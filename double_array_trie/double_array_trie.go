@@ -0,0 +1,307 @@
+package double_array_trie
+
+import "sort"
+
+// A double-array trie stores a byte-level trie in two parallel int32 arrays instead of nodes and pointers.
+// Following the edge labelled c out of state s leads to state t = base[s] + c, and that edge exists only if check[t] == s.
+// So a lookup is a couple of array reads per byte, with no maps and no pointer chasing.
+// The catch is that it's expensive to modify, so it's built once from all the keys and is read-only afterwards.
+
+// edge labels: every key byte b is labelled b+1, and label 0 marks the end of a key
+const (
+	terminator = 0
+	alphabet   = 257
+	// check value of an unused slot
+	free = -1
+)
+
+type Trie[T any] struct {
+	// for a terminal state (reached via the terminator edge), base holds -(value index + 1) instead
+	base  []int32
+	check []int32
+	// states don't know their own children, so to enumerate them (for PredictiveSearch) without trying every label,
+	// firstChild[s] holds the label+1 of s's first child, and nextSibling[t] the label+1 of t's next sibling (0 means none)
+	firstChild  []uint16
+	nextSibling []uint16
+	// values in key order
+	values []T
+}
+
+type KeyValue[T any] struct {
+	Key   string
+	Value T
+}
+
+// builder keeps the free slots in a doubly linked list while building,
+// so finding room for a state's children doesn't have to step over every used slot
+type builder[T any] struct {
+	trie     *Trie[T]
+	keys     []KeyValue[T]
+	nextFree []int
+	prevFree []int
+	// first and last free slot, -1 if there are none
+	head, tail int
+}
+
+// Build builds a trie containing the given keys. If a key appears more than once, the last value wins.
+func Build[T any](kvs []KeyValue[T]) *Trie[T] {
+	keys := make([]KeyValue[T], len(kvs))
+	copy(keys, kvs)
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].Key < keys[j].Key })
+	// drop duplicates, keeping the last one
+	unique := keys[:0]
+	for i, kv := range keys {
+		if i+1 < len(keys) && keys[i+1].Key == kv.Key {
+			continue
+		}
+		unique = append(unique, kv)
+	}
+
+	t := &Trie[T]{}
+	b := &builder[T]{trie: t, keys: unique, head: -1, tail: -1}
+	b.grow(alphabet)
+	// the root is state 0; its check only needs to be something other than free
+	b.use(0, 0)
+	if len(unique) == 0 {
+		// with a base of 0 the root's terminator edge would lead back to the root itself (whose check is 0),
+		// so point it at a free slot instead
+		t.base[0] = 1
+		return t
+	}
+	b.insert(0, 0, 0, len(unique))
+	return t
+}
+
+// grow makes sure the arrays have at least size slots
+func (b *builder[T]) grow(size int) {
+	for len(b.trie.check) < size {
+		b.trie.base = append(b.trie.base, 0)
+		b.trie.check = append(b.trie.check, free)
+		b.trie.firstChild = append(b.trie.firstChild, 0)
+		b.trie.nextSibling = append(b.trie.nextSibling, 0)
+
+		// new slots go on the end of the free list
+		slot := len(b.trie.check) - 1
+		b.nextFree = append(b.nextFree, -1)
+		b.prevFree = append(b.prevFree, b.tail)
+		if b.tail >= 0 {
+			b.nextFree[b.tail] = slot
+		} else {
+			b.head = slot
+		}
+		b.tail = slot
+	}
+}
+
+// use marks slot as belonging to a child of state parent, and takes it off the free list
+func (b *builder[T]) use(slot int, parent int32) {
+	b.trie.check[slot] = parent
+	prev, next := b.prevFree[slot], b.nextFree[slot]
+	if prev >= 0 {
+		b.nextFree[prev] = next
+	} else {
+		b.head = next
+	}
+	if next >= 0 {
+		b.prevFree[next] = prev
+	} else {
+		b.tail = prev
+	}
+}
+
+// label returns the edge label for key at depth: its byte there (+1), or the terminator once the key has ended
+func label(key string, depth int) int {
+	if depth == len(key) {
+		return terminator
+	}
+	return int(key[depth]) + 1
+}
+
+// insert places the children of state s, which are the labels at depth of keys[lo:hi] (sorted, sharing everything before depth)
+func (b *builder[T]) insert(s int32, depth int, lo, hi int) {
+	// group the keys by their label at this depth
+	var labels []int
+	var starts []int
+	for i := lo; i < hi; i++ {
+		l := label(b.keys[i].Key, depth)
+		if len(labels) == 0 || labels[len(labels)-1] != l {
+			labels = append(labels, l)
+			starts = append(starts, i)
+		}
+	}
+	starts = append(starts, hi)
+
+	base := b.findBase(labels)
+	b.trie.base[s] = int32(base)
+	b.trie.firstChild[s] = uint16(labels[0] + 1)
+	for i, l := range labels {
+		b.use(base+l, s)
+		if i+1 < len(labels) {
+			b.trie.nextSibling[base+l] = uint16(labels[i+1] + 1)
+		}
+	}
+
+	for i, l := range labels {
+		t := int32(base + l)
+		if l == terminator {
+			// a key ends here; sorting put it first in its group, and deduplication made it the only one
+			b.trie.base[t] = -int32(len(b.trie.values)) - 1
+			b.trie.values = append(b.trie.values, b.keys[starts[i]].Value)
+			continue
+		}
+		b.insert(t, depth+1, starts[i], starts[i+1])
+	}
+}
+
+// findBase finds a base >= 1 for which every base+label slot is free, trying the free slots in order for the first label
+func (b *builder[T]) findBase(labels []int) int {
+	if b.head < 0 {
+		b.grow(len(b.trie.check) + alphabet)
+	}
+	for pos := b.head; ; pos = b.nextFree[pos] {
+		if pos > labels[0] {
+			b.grow(pos + alphabet)
+			base := pos - labels[0]
+			fits := true
+			for _, l := range labels[1:] {
+				if b.trie.check[base+l] != free {
+					fits = false
+					break
+				}
+			}
+			if fits {
+				return base
+			}
+		}
+		if b.nextFree[pos] < 0 {
+			b.grow(len(b.trie.check) + alphabet)
+		}
+	}
+}
+
+// next follows the edge labelled l out of state s
+func (t *Trie[T]) next(s int32, l int) (int32, bool) {
+	if t.base[s] < 0 {
+		return 0, false
+	}
+	next := t.base[s] + int32(l)
+	if int(next) >= len(t.check) || t.check[next] != s {
+		return 0, false
+	}
+	return next, true
+}
+
+// walk follows key from the root and returns the state it ends in
+func (t *Trie[T]) walk(key string) (int32, bool) {
+	var s int32
+	for i := 0; i < len(key); i++ {
+		var ok bool
+		if s, ok = t.next(s, int(key[i])+1); !ok {
+			return 0, false
+		}
+	}
+	return s, true
+}
+
+// value returns the value stored at state s, if a key ends there
+func (t *Trie[T]) value(s int32) (bool, T) {
+	end, ok := t.next(s, terminator)
+	if !ok {
+		var zero T
+		return false, zero
+	}
+	return true, t.values[-t.base[end]-1]
+}
+
+// Len returns the number of keys in the trie
+func (t *Trie[T]) Len() int {
+	return len(t.values)
+}
+
+// Search returns the value for key, and whether key exists
+func (t *Trie[T]) Search(key string) (bool, T) {
+	s, ok := t.walk(key)
+	if !ok {
+		var zero T
+		return false, zero
+	}
+	return t.value(s)
+}
+
+// CommonPrefixSearch returns every key that is a prefix of s (including s itself), shortest first
+func (t *Trie[T]) CommonPrefixSearch(s string) []KeyValue[T] {
+	var results []KeyValue[T]
+	var state int32
+	for i := 0; ; i++ {
+		if found, val := t.value(state); found {
+			results = append(results, KeyValue[T]{Key: s[:i], Value: val})
+		}
+		if i == len(s) {
+			return results
+		}
+		var ok bool
+		if state, ok = t.next(state, int(s[i])+1); !ok {
+			return results
+		}
+	}
+}
+
+// PredictiveSearch returns every key that starts with prefix, in lexicographic order
+func (t *Trie[T]) PredictiveSearch(prefix string) []KeyValue[T] {
+	var results []KeyValue[T]
+	t.predict(prefix, func(key []byte, val T) bool {
+		results = append(results, KeyValue[T]{Key: string(key), Value: val})
+		return true
+	})
+	return results
+}
+
+// Each calls fn for every key in lexicographic order, stopping early if fn returns false
+func (t *Trie[T]) Each(fn func(key string, val T) bool) {
+	t.predict("", func(key []byte, val T) bool {
+		return fn(string(key), val)
+	})
+}
+
+// SearchPrefix returns all Keys with the given prefix, mapped to their values, like the other stores
+func (t *Trie[T]) SearchPrefix(prefix string) map[string]T {
+	keysAndVals := make(map[string]T)
+	if len(prefix) == 0 {
+		return keysAndVals
+	}
+	t.predict(prefix, func(key []byte, val T) bool {
+		keysAndVals[string(key)] = val
+		return true
+	})
+	return keysAndVals
+}
+
+// predict calls fn for every key that starts with prefix, in order, stopping early if fn returns false
+func (t *Trie[T]) predict(prefix string, fn func(key []byte, val T) bool) {
+	s, ok := t.walk(prefix)
+	if !ok {
+		return
+	}
+	key := append(make([]byte, 0, len(prefix)+64), prefix...)
+	t.collect(s, key, fn)
+}
+
+// collect visits everything below state s depth first, and reports false if fn stopped it
+func (t *Trie[T]) collect(s int32, key []byte, fn func(key []byte, val T) bool) ([]byte, bool) {
+	base := t.base[s]
+	keyLen := len(key)
+	for l := int32(t.firstChild[s]) - 1; l >= 0; l = int32(t.nextSibling[base+l]) - 1 {
+		next := base + l
+		if l == terminator {
+			if !fn(key, t.values[-t.base[next]-1]) {
+				return key[:keyLen], false
+			}
+			continue
+		}
+		var ok bool
+		if key, ok = t.collect(next, append(key[:keyLen], byte(l-1)), fn); !ok {
+			return key[:keyLen], false
+		}
+	}
+	return key[:keyLen], true
+}
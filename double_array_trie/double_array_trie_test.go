@@ -0,0 +1,127 @@
+package double_array_trie
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+func TestSearch(t *testing.T) {
+	trie := Build([]KeyValue[int]{
+		{"business_summary.departments.finance", 0},
+		{"business_summary.departments.software", 100},
+		{"héllo", 7},
+		{"a", 1},
+		{"aa", 2},
+		{"aa", 3},
+		{"", 4},
+	})
+
+	if trie.Len() != 6 {
+		t.Errorf("expected duplicate keys to be merged into 6 keys, got %d", trie.Len())
+	}
+	for key, expected := range map[string]int{"a": 1, "aa": 3, "héllo": 7, "": 4, "business_summary.departments.software": 100} {
+		if found, val := trie.Search(key); !found || val != expected {
+			t.Errorf("expected %q to be %d, got %v %d", key, expected, found, val)
+		}
+	}
+	for _, key := range []string{"aaa", "b", "business_summary", "hé"} {
+		if found, _ := trie.Search(key); found {
+			t.Errorf("expected %q not to be found", key)
+		}
+	}
+}
+
+func TestPrefixSearches(t *testing.T) {
+	trie := Build([]KeyValue[int]{
+		{"profits", 1},
+		{"profits.revenue", 2},
+		{"profits.revenue.net", 3},
+		{"profits.revenue.taxes", -200},
+		{"profit", 5},
+	})
+
+	common := trie.CommonPrefixSearch("profits.revenue.net.extra")
+	expected := []KeyValue[int]{{"profit", 5}, {"profits", 1}, {"profits.revenue", 2}, {"profits.revenue.net", 3}}
+	if !reflect.DeepEqual(common, expected) {
+		t.Errorf("expected common prefixes %v, got %v", expected, common)
+	}
+
+	predicted := trie.PredictiveSearch("profits.rev")
+	expected = []KeyValue[int]{{"profits.revenue", 2}, {"profits.revenue.net", 3}, {"profits.revenue.taxes", -200}}
+	if !reflect.DeepEqual(predicted, expected) {
+		t.Errorf("expected predictions %v, got %v", expected, predicted)
+	}
+
+	results := trie.SearchPrefix("profits.revenue.")
+	if len(results) != 2 || results["profits.revenue.taxes"] != -200 {
+		t.Errorf("expected 2 results, got %v", results)
+	}
+	if results := trie.SearchPrefix("nope"); len(results) != 0 {
+		t.Errorf("expected no results, got %v", results)
+	}
+}
+
+func TestEach(t *testing.T) {
+	trie := Build([]KeyValue[int]{{"b", 0}, {"héllo", 1}, {"a", 2}, {"", 3}, {"ab", 4}})
+	var keys []string
+	trie.Each(func(key string, val int) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if expected := []string{"", "a", "ab", "b", "héllo"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %v, got %v", expected, keys)
+	}
+	keys = nil
+	trie.Each(func(key string, val int) bool {
+		keys = append(keys, key)
+		return len(keys) < 2
+	})
+	if expected := []string{"", "a"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected to stop after %v, got %v", expected, keys)
+	}
+}
+
+func TestStats(t *testing.T) {
+	trie := Build([]KeyValue[int]{{"ab", 1}, {"abc", 2}, {"ad", 3}})
+	stats := trie.Stats()
+	if stats.Keys != 3 || stats.Nodes != 5 || stats.ValuelessNodes != 1 {
+		t.Errorf("expected 3 keys in 5 nodes with 1 valueless one, got %+v", stats)
+	}
+	if stats.MaxDepth != 3 || stats.AvgDepth != 7.0/3 {
+		t.Errorf("expected depths of 3 and 7/3, got %d and %v", stats.MaxDepth, stats.AvgDepth)
+	}
+	expected := map[int]int{0: 2, 1: 2, 2: 1}
+	if !reflect.DeepEqual(stats.Fanout, expected) {
+		t.Errorf("expected fanout %v, got %v", expected, stats.Fanout)
+	}
+	if stats.NodeBytes <= 0 || stats.ChildrenBytes <= 0 || stats.ValueBytes < 3*int(unsafe.Sizeof(0)) {
+		t.Errorf("unexpected bytes %+v", stats)
+	}
+	if empty := Build[int](nil).Stats(); empty.Keys != 0 {
+		t.Errorf("expected an empty trie to have no keys, got %+v", empty)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	for _, trie := range []*Trie[int]{Build[int](nil), Build([]KeyValue[int]{})} {
+		if found, _ := trie.Search(""); found {
+			t.Errorf("expected an empty trie not to have the empty key")
+		}
+		if found, _ := trie.Search("abc"); found {
+			t.Errorf("expected an empty trie not to have abc")
+		}
+		if results := trie.CommonPrefixSearch("abc"); len(results) != 0 {
+			t.Errorf("expected no results, got %v", results)
+		}
+		if results := trie.SearchPrefix("a"); len(results) != 0 {
+			t.Errorf("expected no results, got %v", results)
+		}
+		if results := trie.PredictiveSearch(""); len(results) != 0 {
+			t.Errorf("expected no results, got %v", results)
+		}
+		if trie.Len() != 0 {
+			t.Errorf("expected no keys, got %d", trie.Len())
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package double_array_trie
+
+import (
+	"unsafe"
+
+	"github.com/groovemonkey/trie-keys-experiment/stats"
+)
+
+// Stats walks the whole trie and returns its Stats.
+// A node is a state for one key byte; the terminator edges marking the end of a key aren't counted as nodes.
+// Node bytes are the base/check arrays (including unused slots), and children bytes the arrays listing each state's children.
+func (t *Trie[T]) Stats() stats.Stats {
+	var zero T
+	st := stats.New()
+	var visit func(s int32, depth int)
+	visit = func(s int32, depth int) {
+		base := t.base[s]
+		numChildren, hasValue := 0, false
+		for l := int32(t.firstChild[s]) - 1; l >= 0; l = int32(t.nextSibling[base+l]) - 1 {
+			if l == terminator {
+				hasValue = true
+				continue
+			}
+			numChildren++
+			visit(base+l, depth+1)
+		}
+		st.AddNode(depth, numChildren, hasValue)
+	}
+	if len(t.base) > 0 {
+		visit(0, 0)
+	}
+	st.Finish()
+	st.NodeBytes = (cap(t.base) + cap(t.check)) * int(unsafe.Sizeof(int32(0)))
+	st.ChildrenBytes = (cap(t.firstChild) + cap(t.nextSibling)) * int(unsafe.Sizeof(uint16(0)))
+	st.ValueBytes = cap(t.values) * int(unsafe.Sizeof(zero))
+	return st
+}
@@ -0,0 +1,207 @@
+package fst
+
+import (
+	"regexp/syntax"
+	"unicode/utf8"
+)
+
+// An Automaton recognizes a set of keys one byte at a time. Intersecting it with an FST walks both
+// together, so only the parts of the FST the automaton can still accept are ever visited,
+// e.g. a fuzzy search never looks at subtrees that are already too many edits away.
+type Automaton[S any] interface {
+	// Start returns the state before any input
+	Start() S
+	// Step returns the state after reading b in state s
+	Step(s S, b byte) S
+	// IsMatch reports whether the input read so far is accepted
+	IsMatch(s S) bool
+	// CanMatch reports whether some continuation of the input read so far could still be accepted
+	CanMatch(s S) bool
+}
+
+// Intersect calls fn for every key in f that a accepts, in lexicographic order, stopping early if fn returns false
+func Intersect[S any](f *FST, a Automaton[S], fn func(key string, output uint64) bool) {
+	intersect(f, a, f.root, a.Start(), nil, 0, fn)
+}
+
+func intersect[S any](f *FST, a Automaton[S], s uint32, as S, key []byte, output uint64, fn func(key string, output uint64) bool) ([]byte, bool) {
+	if !a.CanMatch(as) {
+		return key, true
+	}
+	st := f.states[s]
+	if st.final && a.IsMatch(as) && !fn(string(key), output+st.finalOutput) {
+		return key, false
+	}
+	for _, t := range f.transitions[st.firstTrans : st.firstTrans+st.numTrans] {
+		var ok bool
+		key, ok = intersect(f, a, t.target, a.Step(as, t.label), append(key, t.label), output+t.output, fn)
+		key = key[:len(key)-1]
+		if !ok {
+			return key, false
+		}
+	}
+	return key, true
+}
+
+// Levenshtein accepts keys within Distance edits (insertions, deletions, substitutions) of Query.
+// Edits are counted in bytes, so a changed multi-byte rune counts as more than one edit.
+type Levenshtein struct {
+	Query    string
+	Distance int
+}
+
+// the state is one row of the usual edit distance table: row[i] is the distance between the input so far and Query[:i]
+func (l Levenshtein) Start() []int {
+	row := make([]int, len(l.Query)+1)
+	for i := range row {
+		row[i] = i
+	}
+	return row
+}
+
+func (l Levenshtein) Step(row []int, b byte) []int {
+	next := make([]int, len(row))
+	next[0] = row[0] + 1
+	for i := 1; i < len(row); i++ {
+		cost := 1
+		if l.Query[i-1] == b {
+			cost = 0
+		}
+		next[i] = min(row[i]+1, next[i-1]+1, row[i-1]+cost)
+	}
+	return next
+}
+
+func (l Levenshtein) IsMatch(row []int) bool {
+	return row[len(row)-1] <= l.Distance
+}
+
+// the row never gets smaller as more input comes in, so once everything is over the limit we're done
+func (l Levenshtein) CanMatch(row []int) bool {
+	for _, d := range row {
+		if d <= l.Distance {
+			return true
+		}
+	}
+	return false
+}
+
+// Regexp accepts keys that match a regular expression in full (as if it were wrapped in ^...$).
+// It's a plain NFA simulation over regexp/syntax's compiled program, stepping a rune at a time once
+// enough bytes for one have come in. Word boundaries (\b, \B) aren't supported and never match.
+type Regexp struct {
+	prog *syntax.Prog
+}
+
+// RegexpState is the set of program instructions the NFA could be at
+type RegexpState struct {
+	// instructions waiting for the next rune, before following empty transitions
+	pcs []uint32
+	// bytes of a rune that isn't complete yet
+	pending []byte
+	// nothing has been read yet
+	atStart bool
+}
+
+// CompileRegexp parses expr with Perl syntax (like regexp.Compile)
+func CompileRegexp(expr string) (*Regexp, error) {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return nil, err
+	}
+	return &Regexp{prog: prog}, nil
+}
+
+func (r *Regexp) Start() RegexpState {
+	return RegexpState{pcs: []uint32{uint32(r.prog.Start)}, atStart: true}
+}
+
+func (r *Regexp) Step(s RegexpState, b byte) RegexpState {
+	pending := append(s.pending[:len(s.pending):len(s.pending)], b)
+	if !utf8.FullRune(pending) {
+		return RegexpState{pcs: s.pcs, pending: pending, atStart: s.atStart}
+	}
+	char, _ := utf8.DecodeRune(pending)
+	var next []uint32
+	seen := make([]bool, len(r.prog.Inst))
+	for _, pc := range r.closure(s, false) {
+		inst := &r.prog.Inst[pc]
+		if r.matchRune(inst, char) && !seen[inst.Out] {
+			seen[inst.Out] = true
+			next = append(next, inst.Out)
+		}
+	}
+	return RegexpState{pcs: next}
+}
+
+func (r *Regexp) IsMatch(s RegexpState) bool {
+	if len(s.pending) > 0 {
+		return false
+	}
+	for _, pc := range r.closure(s, true) {
+		if r.prog.Inst[pc].Op == syntax.InstMatch {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Regexp) CanMatch(s RegexpState) bool {
+	return len(s.pcs) > 0
+}
+
+func (r *Regexp) matchRune(inst *syntax.Inst, char rune) bool {
+	switch inst.Op {
+	case syntax.InstRune:
+		return inst.MatchRune(char)
+	case syntax.InstRune1:
+		return char == inst.Rune[0]
+	case syntax.InstRuneAny:
+		return true
+	case syntax.InstRuneAnyNotNL:
+		return char != '\n'
+	}
+	return false
+}
+
+// closure follows empty transitions from s's instructions and returns the ones that consume a rune or match.
+// Begin-of-text assertions only hold before any input, end-of-text ones only when atEnd is set.
+func (r *Regexp) closure(s RegexpState, atEnd bool) []uint32 {
+	var out []uint32
+	seen := make([]bool, len(r.prog.Inst))
+	stack := append([]uint32(nil), s.pcs...)
+	for len(stack) > 0 {
+		pc := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[pc] {
+			continue
+		}
+		seen[pc] = true
+		inst := &r.prog.Inst[pc]
+		switch inst.Op {
+		case syntax.InstAlt, syntax.InstAltMatch:
+			stack = append(stack, inst.Arg, inst.Out)
+		case syntax.InstCapture, syntax.InstNop:
+			stack = append(stack, inst.Out)
+		case syntax.InstEmptyWidth:
+			var holds syntax.EmptyOp
+			if s.atStart {
+				holds |= syntax.EmptyBeginText | syntax.EmptyBeginLine
+			}
+			if atEnd {
+				holds |= syntax.EmptyEndText | syntax.EmptyEndLine
+			}
+			if syntax.EmptyOp(inst.Arg)&^holds == 0 {
+				stack = append(stack, inst.Out)
+			}
+		case syntax.InstFail:
+		default:
+			out = append(out, pc)
+		}
+	}
+	return out
+}
@@ -0,0 +1,237 @@
+package fst
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// An FST (finite state transducer) is a minimal acyclic automaton that maps keys to uint64 outputs.
+// Like a trie it shares common prefixes, but it also merges identical suffixes: every state that has the
+// same transitions (and finality) as one seen before is replaced by it. Outputs live on the transitions and
+// are summed along the path, and they're pushed as close to the start as possible so that suffixes with
+// different values can still be shared.
+//
+// It's built once from keys in sorted order (see Builder) and is read-only afterwards.
+
+type FST struct {
+	// all states, a state's transitions are transitions[firstTrans : firstTrans+numTrans], sorted by label
+	states      []state
+	transitions []transition
+	root        uint32
+	numKeys     int
+}
+
+type state struct {
+	final       bool
+	finalOutput uint64
+	firstTrans  uint32
+	numTrans    uint32
+}
+
+type transition struct {
+	label  byte
+	output uint64
+	target uint32
+}
+
+type KeyValue struct {
+	Key   string
+	Value uint64
+}
+
+// ErrUnsorted is returned by Builder.Insert when keys aren't strictly increasing
+var ErrUnsorted = errors.New("keys must be strictly increasing")
+
+// Builder builds an FST from keys inserted in sorted order.
+// Only the path of the last key is kept "unfinished"; everything that the next key branches away from
+// can't change anymore, so it's compiled right away and deduplicated against the states built so far.
+type Builder struct {
+	fst *FST
+	// unfinished[i] is the state after the first i bytes of lastKey
+	unfinished []*unfinishedState
+	// compiled states by their signature, to find identical ones
+	registry  map[string]uint32
+	lastKey   []byte
+	signature []byte
+}
+
+type unfinishedState struct {
+	final       bool
+	finalOutput uint64
+	// the last transition points at the next unfinished state, so its target isn't known yet
+	transitions []transition
+}
+
+func NewBuilder() *Builder {
+	return &Builder{
+		fst:        &FST{},
+		unfinished: []*unfinishedState{{}},
+		registry:   make(map[string]uint32),
+	}
+}
+
+// Insert adds key with the given output. Keys must be strictly increasing (bytewise).
+func (b *Builder) Insert(key string, output uint64) error {
+	if b.fst.numKeys > 0 && key <= string(b.lastKey) {
+		return fmt.Errorf("%w: %q came after %q", ErrUnsorted, key, b.lastKey)
+	}
+	prefixLen := 0
+	for prefixLen < len(key) && prefixLen < len(b.lastKey) && key[prefixLen] == b.lastKey[prefixLen] {
+		prefixLen++
+	}
+	// the last key's states past the shared prefix are done
+	b.compileFrom(prefixLen)
+
+	// move output onto the shared prefix: each transition keeps what both keys have in common,
+	// and the rest is pushed down onto everything after it
+	for i := 0; i < prefixLen; i++ {
+		t := &b.unfinished[i].transitions[len(b.unfinished[i].transitions)-1]
+		common := min(t.output, output)
+		if extra := t.output - common; extra > 0 {
+			b.unfinished[i+1].addOutputPrefix(extra)
+		}
+		t.output = common
+		output -= common
+	}
+
+	if prefixLen == len(key) {
+		// only the empty key can end on a state that's already there
+		b.unfinished[prefixLen].final = true
+		b.unfinished[prefixLen].finalOutput = output
+	} else {
+		// the rest of the output goes on the first new transition
+		parent := b.unfinished[prefixLen]
+		parent.transitions = append(parent.transitions, transition{label: key[prefixLen], output: output})
+		for i := prefixLen + 1; i < len(key); i++ {
+			b.unfinished = append(b.unfinished, &unfinishedState{transitions: []transition{{label: key[i]}}})
+		}
+		b.unfinished = append(b.unfinished, &unfinishedState{final: true})
+	}
+
+	b.lastKey = append(b.lastKey[:0], key...)
+	b.fst.numKeys++
+	return nil
+}
+
+// Finish compiles what's left and returns the FST. The Builder can't be used afterwards.
+func (b *Builder) Finish() *FST {
+	b.compileFrom(0)
+	b.fst.root = b.compile(b.unfinished[0])
+	return b.fst
+}
+
+func (s *unfinishedState) addOutputPrefix(extra uint64) {
+	if s.final {
+		s.finalOutput += extra
+	}
+	for i := range s.transitions {
+		s.transitions[i].output += extra
+	}
+}
+
+// compileFrom compiles the unfinished states deeper than depth, pointing each parent at the compiled child
+func (b *Builder) compileFrom(depth int) {
+	for len(b.unfinished)-1 > depth {
+		last := len(b.unfinished) - 1
+		id := b.compile(b.unfinished[last])
+		parent := b.unfinished[last-1]
+		parent.transitions[len(parent.transitions)-1].target = id
+		b.unfinished = b.unfinished[:last]
+	}
+}
+
+// compile adds s to the FST, or returns an identical state that's already there
+func (b *Builder) compile(s *unfinishedState) uint32 {
+	sig := b.signature[:0]
+	if s.final {
+		sig = append(sig, 1)
+		sig = binary.AppendUvarint(sig, s.finalOutput)
+	} else {
+		sig = append(sig, 0)
+	}
+	for _, t := range s.transitions {
+		sig = append(sig, t.label)
+		sig = binary.AppendUvarint(sig, t.output)
+		sig = binary.AppendUvarint(sig, uint64(t.target))
+	}
+	b.signature = sig
+	if id, ok := b.registry[string(sig)]; ok {
+		return id
+	}
+
+	f := b.fst
+	id := uint32(len(f.states))
+	f.states = append(f.states, state{
+		final:       s.final,
+		finalOutput: s.finalOutput,
+		firstTrans:  uint32(len(f.transitions)),
+		numTrans:    uint32(len(s.transitions)),
+	})
+	f.transitions = append(f.transitions, s.transitions...)
+	b.registry[string(sig)] = id
+	return id
+}
+
+// Build builds an FST from key/value pairs in any order. If a key appears more than once, the last value wins.
+func Build(kvs []KeyValue) *FST {
+	keys := make([]KeyValue, len(kvs))
+	copy(keys, kvs)
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].Key < keys[j].Key })
+	b := NewBuilder()
+	for i, kv := range keys {
+		if i+1 < len(keys) && keys[i+1].Key == kv.Key {
+			continue
+		}
+		// can't fail, the keys are sorted and unique
+		b.Insert(kv.Key, kv.Value)
+	}
+	return b.Finish()
+}
+
+// Len returns the number of keys
+func (f *FST) Len() int {
+	return f.numKeys
+}
+
+// NumStates returns the number of states, which shows how much prefix and suffix sharing saved
+// (a trie needs one node per distinct prefix)
+func (f *FST) NumStates() int {
+	return len(f.states)
+}
+
+// step follows the transition labelled label out of s
+func (f *FST) step(s uint32, label byte) (transition, bool) {
+	st := f.states[s]
+	trans := f.transitions[st.firstTrans : st.firstTrans+st.numTrans]
+	i := sort.Search(len(trans), func(i int) bool { return trans[i].label >= label })
+	if i == len(trans) || trans[i].label != label {
+		return transition{}, false
+	}
+	return trans[i], true
+}
+
+// walk follows key from the root, returning the state it ends in and the output collected on the way
+func (f *FST) walk(key string) (uint32, uint64, bool) {
+	s := f.root
+	var output uint64
+	for i := 0; i < len(key); i++ {
+		t, ok := f.step(s, key[i])
+		if !ok {
+			return 0, 0, false
+		}
+		output += t.output
+		s = t.target
+	}
+	return s, output, true
+}
+
+// Search returns the output for key, and whether key exists
+func (f *FST) Search(key string) (bool, uint64) {
+	s, output, ok := f.walk(key)
+	if !ok || !f.states[s].final {
+		return false, 0
+	}
+	return true, output + f.states[s].finalOutput
+}
@@ -0,0 +1,173 @@
+package fst
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func testFST() *FST {
+	return Build([]KeyValue{
+		{"profits", 1},
+		{"profits.revenue", 2},
+		{"profits.revenue.net", 3},
+		{"profits.revenue.taxes", 200},
+		{"profit", 5},
+		{"losses.revenue.net", 3},
+		{"losses.revenue.taxes", 200},
+		{"héllo", 7},
+		{"", 4},
+	})
+}
+
+func TestSearch(t *testing.T) {
+	f := testFST()
+	if f.Len() != 9 {
+		t.Errorf("expected 9 keys, got %d", f.Len())
+	}
+	for key, expected := range map[string]uint64{"profits": 1, "profit": 5, "profits.revenue.taxes": 200, "losses.revenue.net": 3, "héllo": 7, "": 4} {
+		if found, val := f.Search(key); !found || val != expected {
+			t.Errorf("expected %q to be %d, got %v %d", key, expected, found, val)
+		}
+	}
+	for _, key := range []string{"prof", "profits.", "losses", "hé", "zzz"} {
+		if found, _ := f.Search(key); found {
+			t.Errorf("expected %q not to be found", key)
+		}
+	}
+}
+
+func TestSuffixSharing(t *testing.T) {
+	kvs := []KeyValue{
+		{"a.testing.very.long.key", 1},
+		{"b.testing.very.long.key", 2},
+		{"c.testing.very.long.key", 3},
+	}
+	f := Build(kvs)
+	// the root, one state for each of the shared suffix's bytes and one for its end
+	if f.NumStates() != len(".testing.very.long.key")+2 {
+		t.Errorf("expected the suffix to be shared, got %d states", f.NumStates())
+	}
+	for _, kv := range kvs {
+		if found, val := f.Search(kv.Key); !found || val != kv.Value {
+			t.Errorf("expected %q to be %d, got %v %d", kv.Key, kv.Value, found, val)
+		}
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	b := NewBuilder()
+	if err := b.Insert("b", 1); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := b.Insert("a", 2); !errors.Is(err, ErrUnsorted) {
+		t.Errorf("expected ErrUnsorted, got %v", err)
+	}
+	if err := b.Insert("b", 3); !errors.Is(err, ErrUnsorted) {
+		t.Errorf("expected ErrUnsorted for a duplicate key, got %v", err)
+	}
+	b.Insert("ba", 0)
+	f := b.Finish()
+	if found, val := f.Search("b"); !found || val != 1 {
+		t.Errorf("expected b to be 1, got %v %d", found, val)
+	}
+	if found, val := f.Search("ba"); !found || val != 0 {
+		t.Errorf("expected ba to be 0, got %v %d", found, val)
+	}
+}
+
+func TestIteration(t *testing.T) {
+	f := testFST()
+
+	var keys []string
+	f.Each(func(key string, output uint64) bool {
+		keys = append(keys, key)
+		return true
+	})
+	expected := []string{"", "héllo", "losses.revenue.net", "losses.revenue.taxes", "profit", "profits", "profits.revenue", "profits.revenue.net", "profits.revenue.taxes"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %v, got %v", expected, keys)
+	}
+
+	results := f.SearchPrefix("profits.revenue.")
+	if len(results) != 2 || results["profits.revenue.taxes"] != 200 || results["profits.revenue.net"] != 3 {
+		t.Errorf("expected 2 results, got %v", results)
+	}
+	if results := f.SearchPrefix("nope"); len(results) != 0 {
+		t.Errorf("expected no results, got %v", results)
+	}
+
+	count := 0
+	f.SearchPrefixFunc("profit", func(key string, output uint64) bool {
+		count++
+		return count < 2
+	})
+	if count != 2 {
+		t.Errorf("expected iteration to stop after 2 keys, got %d", count)
+	}
+
+	kvs := f.Range("losses.revenue.o", "profits.")
+	expectedKvs := []KeyValue{{"losses.revenue.taxes", 200}, {"profit", 5}, {"profits", 1}}
+	if !reflect.DeepEqual(kvs, expectedKvs) {
+		t.Errorf("expected %v, got %v", expectedKvs, kvs)
+	}
+	if kvs := f.Range("profits.revenue.net", ""); len(kvs) != 2 {
+		t.Errorf("expected 2 keys to the end, got %v", kvs)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	f := testFST()
+	var keys []string
+	Intersect[[]int](f, Levenshtein{Query: "profite", Distance: 1}, func(key string, output uint64) bool {
+		keys = append(keys, key)
+		return true
+	})
+	expected := []string{"profit", "profits"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %v, got %v", expected, keys)
+	}
+}
+
+func TestRegexp(t *testing.T) {
+	f := testFST()
+	for expr, expected := range map[string][]string{
+		`.*\.revenue\.net`: {"losses.revenue.net", "profits.revenue.net"},
+		`profits?`:         {"profit", "profits"},
+		`h.llo`:            {"héllo"},
+		`^$`:               {""},
+		`revenue`:          nil,
+	} {
+		re, err := CompileRegexp(expr)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", expr, err)
+		}
+		var keys []string
+		Intersect[RegexpState](f, re, func(key string, output uint64) bool {
+			keys = append(keys, key)
+			return true
+		})
+		if !reflect.DeepEqual(keys, expected) {
+			t.Errorf("expected %q to match %v, got %v", expr, expected, keys)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	// "ab" and "cb" share their last state
+	f := Build([]KeyValue{{"ab", 1}, {"abc", 2}, {"cb", 3}})
+	stats := f.Stats()
+	if stats.Keys != 3 || stats.Nodes != f.NumStates() || stats.ValuelessNodes != 2 {
+		t.Errorf("expected 3 keys in %d states with 2 valueless ones, got %+v", f.NumStates(), stats)
+	}
+	if stats.MaxDepth != 3 || stats.AvgDepth != 7.0/3 {
+		t.Errorf("expected depths of 3 and 7/3, got %d and %v", stats.MaxDepth, stats.AvgDepth)
+	}
+	expected := map[int]int{0: 1, 1: 3, 2: 1}
+	if !reflect.DeepEqual(stats.Fanout, expected) {
+		t.Errorf("expected fanout %v, got %v", expected, stats.Fanout)
+	}
+	if stats.NodeBytes <= 0 || stats.ChildrenBytes <= 0 || stats.ValueBytes <= 0 {
+		t.Errorf("unexpected bytes %+v", stats)
+	}
+}
@@ -0,0 +1,92 @@
+package fst
+
+// Iteration is a depth-first walk over the transitions, which are sorted by label, so keys come out in
+// bytewise lexicographic order. Keys are rebuilt in a single reused buffer as we go.
+
+// each calls fn for every key reachable from s, stopping early if fn returns false.
+// key holds the bytes that lead to s and output the output collected on the way.
+func (f *FST) each(s uint32, key []byte, output uint64, fn func(key []byte, output uint64) bool) ([]byte, bool) {
+	st := f.states[s]
+	if st.final && !fn(key, output+st.finalOutput) {
+		return key, false
+	}
+	for _, t := range f.transitions[st.firstTrans : st.firstTrans+st.numTrans] {
+		var ok bool
+		key, ok = f.each(t.target, append(key, t.label), output+t.output, fn)
+		key = key[:len(key)-1]
+		if !ok {
+			return key, false
+		}
+	}
+	return key, true
+}
+
+// Each calls fn for every key in lexicographic order, stopping early if fn returns false
+func (f *FST) Each(fn func(key string, output uint64) bool) {
+	f.SearchPrefixFunc("", fn)
+}
+
+// SearchPrefixFunc calls fn for every key with the given prefix in lexicographic order, stopping early if fn returns false
+func (f *FST) SearchPrefixFunc(prefix string, fn func(key string, output uint64) bool) {
+	s, output, ok := f.walk(prefix)
+	if !ok {
+		return
+	}
+	f.each(s, []byte(prefix), output, func(key []byte, output uint64) bool {
+		return fn(string(key), output)
+	})
+}
+
+// SearchPrefix returns all keys with the given prefix, mapped to their outputs
+func (f *FST) SearchPrefix(prefix string) map[string]uint64 {
+	keysAndVals := make(map[string]uint64)
+	if len(prefix) == 0 {
+		return keysAndVals
+	}
+	f.SearchPrefixFunc(prefix, func(key string, output uint64) bool {
+		keysAndVals[key] = output
+		return true
+	})
+	return keysAndVals
+}
+
+// Range returns all keys k with startKey <= k < endKey, in lexicographic order.
+// An empty endKey means "no upper bound".
+func (f *FST) Range(startKey, endKey string) []KeyValue {
+	var results []KeyValue
+	f.rangeFrom(f.root, nil, 0, startKey, true, func(key []byte, output uint64) bool {
+		if endKey != "" && string(key) >= endKey {
+			return false
+		}
+		results = append(results, KeyValue{Key: string(key), Value: output})
+		return true
+	})
+	return results
+}
+
+// rangeFrom is like each, but skips keys below startKey. While tight is true, key is a prefix of startKey
+// and only transitions that don't sort before startKey are followed; after that everything below is in range.
+func (f *FST) rangeFrom(s uint32, key []byte, output uint64, startKey string, tight bool, fn func(key []byte, output uint64) bool) ([]byte, bool) {
+	if !tight {
+		return f.each(s, key, output, fn)
+	}
+	st := f.states[s]
+	depth := len(key)
+	// key == startKey[:depth], so it's only in range if it's all of startKey
+	if st.final && depth == len(startKey) && !fn(key, output+st.finalOutput) {
+		return key, false
+	}
+	for _, t := range f.transitions[st.firstTrans : st.firstTrans+st.numTrans] {
+		stillTight := depth < len(startKey) && t.label == startKey[depth]
+		if depth < len(startKey) && t.label < startKey[depth] {
+			continue
+		}
+		var ok bool
+		key, ok = f.rangeFrom(t.target, append(key, t.label), output+t.output, startKey, stillTight, fn)
+		key = key[:len(key)-1]
+		if !ok {
+			return key, false
+		}
+	}
+	return key, true
+}
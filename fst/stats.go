@@ -0,0 +1,32 @@
+package fst
+
+import (
+	"unsafe"
+
+	"github.com/groovemonkey/trie-keys-experiment/stats"
+)
+
+// Stats returns the FST's Stats. Nodes are states, which are shared between keys, so there are usually far fewer
+// of them than in a trie, and a key's depth is its length in bytes.
+// Values are the outputs on states and transitions; children bytes are the transitions without their outputs.
+func (f *FST) Stats() stats.Stats {
+	st := stats.Stats{Nodes: len(f.states), Fanout: make(map[int]int)}
+	for i, s := range f.states {
+		st.Fanout[int(s.numTrans)]++
+		if !s.final && uint32(i) != f.root {
+			st.ValuelessNodes++
+		}
+	}
+	if len(f.states) > 0 {
+		f.each(f.root, nil, 0, func(key []byte, output uint64) bool {
+			st.AddKeys(len(key), 1)
+			return true
+		})
+	}
+	st.Finish()
+	outputSize := int(unsafe.Sizeof(uint64(0)))
+	st.ValueBytes = (cap(f.states) + cap(f.transitions)) * outputSize
+	st.NodeBytes = cap(f.states)*int(unsafe.Sizeof(state{})) - cap(f.states)*outputSize
+	st.ChildrenBytes = cap(f.transitions)*int(unsafe.Sizeof(transition{})) - cap(f.transitions)*outputSize
+	return st
+}
@@ -0,0 +1,241 @@
+package hamt
+
+import (
+	"hash/maphash"
+	"math/bits"
+	"strings"
+)
+
+// A Hash Array Mapped Trie is a hash map shaped like a trie over the bits of each key's hash:
+// every level uses 5 bits to pick one of 32 slots. A node only stores the slots that are in use,
+// with a bitmap saying which ones those are, so it stays small.
+//
+// Maps are immutable: Insert and Delete return a new Map that shares everything except the nodes on
+// the changed path (about log32(n) of them) with the old one. So keeping an old version around,
+// i.e. taking a snapshot, is O(1) and copies nothing.
+
+const (
+	bitsPerLevel = 5
+	// past this depth all 64 hash bits are used up, and keys with equal hashes go into a collision node
+	maxDepth = (64 + bitsPerLevel - 1) / bitsPerLevel
+)
+
+// the same hash Go maps use
+var hashSeed = maphash.MakeSeed()
+
+type node[T any] struct {
+	// bit i is set if slot i is in use; entries holds the used slots in order.
+	// Collision nodes (at maxDepth) don't use the bitmap, their entries are just a list.
+	bitmap  uint32
+	entries []entry[T]
+}
+
+// entry is either a key and its value, or (if child isn't nil) a subtree
+type entry[T any] struct {
+	child *node[T]
+	hash  uint64
+	key   string
+	value T
+}
+
+type Map[T any] struct {
+	root *node[T]
+	size int
+}
+
+func New[T any]() *Map[T] {
+	return &Map[T]{root: &node[T]{}}
+}
+
+// Len returns the number of keys
+func (m *Map[T]) Len() int {
+	return m.size
+}
+
+// slot returns the bit for hash at depth, and the position of that slot in n.entries
+func (n *node[T]) slot(hash uint64, depth int) (uint32, int) {
+	bit := uint32(1) << ((hash >> (depth * bitsPerLevel)) & 31)
+	return bit, bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+// Search returns whether key exists, and its value
+func (m *Map[T]) Search(key string) (bool, T) {
+	hash := maphash.String(hashSeed, key)
+	n := m.root
+	for depth := 0; ; depth++ {
+		if depth == maxDepth {
+			for _, e := range n.entries {
+				if e.key == key {
+					return true, e.value
+				}
+			}
+			break
+		}
+		bit, pos := n.slot(hash, depth)
+		if n.bitmap&bit == 0 {
+			break
+		}
+		e := &n.entries[pos]
+		if e.child == nil {
+			if e.key == key {
+				return true, e.value
+			}
+			break
+		}
+		n = e.child
+	}
+	var zero T
+	return false, zero
+}
+
+// Insert returns a new Map with key set to val. m itself doesn't change.
+func (m *Map[T]) Insert(key string, val T) *Map[T] {
+	root, added := m.root.insert(0, entry[T]{hash: maphash.String(hashSeed, key), key: key, value: val})
+	size := m.size
+	if added {
+		size++
+	}
+	return &Map[T]{root: root, size: size}
+}
+
+// insert returns a copy of n with e added, and whether e's key is new
+func (n *node[T]) insert(depth int, e entry[T]) (*node[T], bool) {
+	if depth == maxDepth {
+		for i := range n.entries {
+			if n.entries[i].key == e.key {
+				return n.with(i, e), false
+			}
+		}
+		return &node[T]{entries: append(n.entries[:len(n.entries):len(n.entries)], e)}, true
+	}
+
+	bit, pos := n.slot(e.hash, depth)
+	if n.bitmap&bit == 0 {
+		entries := make([]entry[T], len(n.entries)+1)
+		copy(entries, n.entries[:pos])
+		entries[pos] = e
+		copy(entries[pos+1:], n.entries[pos:])
+		return &node[T]{bitmap: n.bitmap | bit, entries: entries}, true
+	}
+
+	existing := n.entries[pos]
+	switch {
+	case existing.child != nil:
+		child, added := existing.child.insert(depth+1, e)
+		return n.with(pos, entry[T]{child: child}), added
+	case existing.key == e.key:
+		return n.with(pos, e), false
+	}
+	// two keys in the same slot, push both down a level
+	return n.with(pos, entry[T]{child: pair(depth+1, existing, e)}), true
+}
+
+// pair returns a node holding two entries with different keys
+func pair[T any](depth int, a, b entry[T]) *node[T] {
+	if depth == maxDepth {
+		return &node[T]{entries: []entry[T]{a, b}}
+	}
+	shift := depth * bitsPerLevel
+	aSlot, bSlot := (a.hash>>shift)&31, (b.hash>>shift)&31
+	switch {
+	case aSlot == bSlot:
+		return &node[T]{bitmap: 1 << aSlot, entries: []entry[T]{{child: pair(depth+1, a, b)}}}
+	case aSlot < bSlot:
+		return &node[T]{bitmap: 1<<aSlot | 1<<bSlot, entries: []entry[T]{a, b}}
+	}
+	return &node[T]{bitmap: 1<<aSlot | 1<<bSlot, entries: []entry[T]{b, a}}
+}
+
+// with returns a copy of n with entries[i] replaced by e
+func (n *node[T]) with(i int, e entry[T]) *node[T] {
+	entries := make([]entry[T], len(n.entries))
+	copy(entries, n.entries)
+	entries[i] = e
+	return &node[T]{bitmap: n.bitmap, entries: entries}
+}
+
+// without returns a copy of n without entries[i] (and its bit)
+func (n *node[T]) without(i int, bit uint32) *node[T] {
+	entries := make([]entry[T], 0, len(n.entries)-1)
+	entries = append(entries, n.entries[:i]...)
+	entries = append(entries, n.entries[i+1:]...)
+	return &node[T]{bitmap: n.bitmap &^ bit, entries: entries}
+}
+
+// Delete returns a new Map without key, and whether key was there. m itself doesn't change.
+func (m *Map[T]) Delete(key string) (*Map[T], bool) {
+	root, deleted := m.root.delete(0, maphash.String(hashSeed, key), key)
+	if !deleted {
+		return m, false
+	}
+	return &Map[T]{root: root, size: m.size - 1}, true
+}
+
+func (n *node[T]) delete(depth int, hash uint64, key string) (*node[T], bool) {
+	if depth == maxDepth {
+		for i := range n.entries {
+			if n.entries[i].key == key {
+				return n.without(i, 0), true
+			}
+		}
+		return n, false
+	}
+
+	bit, pos := n.slot(hash, depth)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+	existing := n.entries[pos]
+	if existing.child == nil {
+		if existing.key != key {
+			return n, false
+		}
+		return n.without(pos, bit), true
+	}
+
+	child, deleted := existing.child.delete(depth+1, hash, key)
+	if !deleted {
+		return n, false
+	}
+	switch {
+	case len(child.entries) == 0:
+		return n.without(pos, bit), true
+	case len(child.entries) == 1 && child.entries[0].child == nil:
+		// a subtree with a single key left doesn't need its own node, pull the key back up
+		return n.with(pos, child.entries[0]), true
+	}
+	return n.with(pos, entry[T]{child: child}), true
+}
+
+// Each calls fn for every key, stopping early if fn returns false.
+// Like a Go map, the order is by hash, so it's arbitrary (but the same every time for the same Map).
+func (m *Map[T]) Each(fn func(key string, val T) bool) {
+	m.root.each(fn)
+}
+
+func (n *node[T]) each(fn func(key string, val T) bool) bool {
+	for i := range n.entries {
+		e := &n.entries[i]
+		if e.child != nil {
+			if !e.child.each(fn) {
+				return false
+			}
+		} else if !fn(e.key, e.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// SearchPrefix returns all keys with the given prefix, mapped to their values.
+// Keys are spread out by hash, so just like mapkeys.Store this has to look at every key.
+func (m *Map[T]) SearchPrefix(prefix string) map[string]T {
+	results := make(map[string]T)
+	m.Each(func(key string, val T) bool {
+		if strings.HasPrefix(key, prefix) {
+			results[key] = val
+		}
+		return true
+	})
+	return results
+}
@@ -0,0 +1,125 @@
+package hamt
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestInsertSearchDelete(t *testing.T) {
+	m := New[int]()
+	for i := 0; i < 5000; i++ {
+		m = m.Insert(fmt.Sprintf("key.%d", i), i)
+	}
+	m = m.Insert("key.0", -1)
+	if m.Len() != 5000 {
+		t.Errorf("expected 5000 keys, got %d", m.Len())
+	}
+	for i := 0; i < 5000; i++ {
+		expected := i
+		if i == 0 {
+			expected = -1
+		}
+		if found, val := m.Search(fmt.Sprintf("key.%d", i)); !found || val != expected {
+			t.Errorf("expected key.%d to be %d, got %v %d", i, expected, found, val)
+		}
+	}
+
+	for i := 0; i < 5000; i += 2 {
+		var deleted bool
+		m, deleted = m.Delete(fmt.Sprintf("key.%d", i))
+		if !deleted {
+			t.Errorf("expected key.%d to be deleted", i)
+		}
+	}
+	if _, deleted := m.Delete("key.0"); deleted {
+		t.Errorf("expected deleting a missing key to report false")
+	}
+	count := 0
+	m.Each(func(key string, val int) bool {
+		count++
+		return true
+	})
+	if m.Len() != 2500 || count != 2500 {
+		t.Errorf("expected 2500 keys left, got %d (%d iterated)", m.Len(), count)
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	v1 := New[int]().Insert("profits", 1).Insert("profits.revenue", 2)
+	v2 := v1.Insert("profits", 10)
+	v3, _ := v2.Delete("profits.revenue")
+
+	if found, val := v1.Search("profits"); !found || val != 1 {
+		t.Errorf("expected the old version to keep profits=1, got %v %d", found, val)
+	}
+	if found, val := v2.Search("profits"); !found || val != 10 {
+		t.Errorf("expected the new version to have profits=10, got %v %d", found, val)
+	}
+	if found, _ := v2.Search("profits.revenue"); !found {
+		t.Errorf("expected a delete not to change the version it started from")
+	}
+	if found, _ := v3.Search("profits.revenue"); found || v3.Len() != 1 {
+		t.Errorf("expected profits.revenue to be gone from the newest version")
+	}
+
+	results := v2.SearchPrefix("profits.")
+	if len(results) != 1 || results["profits.revenue"] != 2 {
+		t.Errorf("expected 1 result, got %v", results)
+	}
+}
+
+func TestHashCollisions(t *testing.T) {
+	// all these keys get the same hash, so they end up in one collision node at the bottom
+	root := &node[int]{}
+	for i := 0; i < 3; i++ {
+		root, _ = root.insert(0, entry[int]{hash: 42, key: fmt.Sprint(i), value: i})
+	}
+	root, _ = root.insert(0, entry[int]{hash: 43, key: "other", value: 3})
+	m := &Map[int]{root: root, size: 4}
+
+	found := map[string]int{}
+	m.Each(func(key string, val int) bool {
+		found[key] = val
+		return true
+	})
+	if len(found) != 4 || found["1"] != 1 || found["other"] != 3 {
+		t.Errorf("expected all 4 keys, got %v", found)
+	}
+
+	root, deleted := root.delete(0, 42, "1")
+	if !deleted {
+		t.Errorf("expected a colliding key to be deleted")
+	}
+	root, _ = root.delete(0, 42, "0")
+	// with one key left, the collision node is pulled back up to the top
+	if len(root.entries) != 2 || root.entries[0].child != nil || root.entries[1].child != nil {
+		t.Errorf("expected the last colliding key to move back up, got %+v", root.entries)
+	}
+}
+
+func TestStats(t *testing.T) {
+	m := New[int]()
+	for i := 0; i < 5000; i++ {
+		m = m.Insert(fmt.Sprintf("key%04d", i), i)
+	}
+	stats := m.Stats()
+	if stats.Keys != 5000 || stats.StringBytes != 5000*len("key0000") {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	// 5000 keys spread over 32 slots per level put most keys at depth 3 or more
+	if stats.MaxDepth < 3 || stats.AvgDepth < 2.5 || stats.AvgDepth > float64(stats.MaxDepth) {
+		t.Errorf("expected most keys at depth 3, got %v (max %d)", stats.AvgDepth, stats.MaxDepth)
+	}
+	// every entry is either a key or a subtree, and every node but the root is some entry's subtree
+	nodes, entries := 0, 0
+	for fanout, n := range stats.Fanout {
+		nodes += n
+		entries += fanout * n
+	}
+	if nodes != stats.Nodes || entries != stats.Keys+stats.Nodes-1 {
+		t.Errorf("expected %d entries in %d nodes, got %d in %d", stats.Keys+stats.Nodes-1, stats.Nodes, entries, nodes)
+	}
+	if stats.NodeBytes <= 0 || stats.ChildrenBytes <= 0 || stats.ValueBytes <= 0 {
+		t.Errorf("unexpected bytes %+v", stats)
+	}
+}
@@ -0,0 +1,41 @@
+package hamt
+
+import (
+	"unsafe"
+
+	"github.com/groovemonkey/trie-keys-experiment/stats"
+)
+
+// Stats walks the whole map and returns its Stats.
+// A node's children are its entries, keys and subtrees alike, so a key in the root is at depth 1, and a node is
+// valueless if all its entries are subtrees. Every entry has room for a value, which counts as value bytes.
+// Only this version of the map is counted, even though it probably shares most of its nodes with other versions.
+func (m *Map[T]) Stats() stats.Stats {
+	var zero T
+	valueSize := int(unsafe.Sizeof(zero))
+	st := stats.New()
+	var visit func(n *node[T], depth int)
+	visit = func(n *node[T], depth int) {
+		st.Nodes++
+		st.Fanout[len(n.entries)]++
+		st.NodeBytes += int(unsafe.Sizeof(*n))
+		st.ChildrenBytes += cap(n.entries) * (int(unsafe.Sizeof(entry[T]{})) - valueSize)
+		st.ValueBytes += cap(n.entries) * valueSize
+		hasKeys := false
+		for _, e := range n.entries {
+			if e.child != nil {
+				visit(e.child, depth+1)
+				continue
+			}
+			hasKeys = true
+			st.AddKeys(depth+1, 1)
+			st.StringBytes += len(e.key)
+		}
+		if !hasKeys && depth > 0 {
+			st.ValuelessNodes++
+		}
+	}
+	visit(m.root, 0)
+	st.Finish()
+	return st
+}
@@ -0,0 +1,112 @@
+package hybrid
+
+import (
+	"github.com/groovemonkey/trie-keys-experiment/prefix_trie_chunked"
+	"golang.org/x/exp/constraints"
+)
+
+// Store keeps every key in both a map and a chunked trie, and sends each operation to whichever is
+// faster at it: point lookups go to the map, prefix searches to the trie.
+// The value itself is stored once: the map and the trie both hold a pointer to the same cell, so
+// overwriting a key only touches the cell and the two can't disagree about its value.
+// Since prefix searches go to the trie, prefixes have to be whole chunks: "profits.revenue" finds
+// "profits.revenue.net", "profits.rev" finds nothing.
+type Store[T Number] struct {
+	index map[string]*T
+	trie  *prefix_trie_chunked.Trie[*T]
+}
+
+type Number interface {
+	constraints.Integer | constraints.Float
+}
+
+// Aggregation function interface: take any number of keys and aggregate them somehow (sum, mean, etc.)
+// The result will always be a float64
+type AggregationFunction[T Number] func(keysAndVals map[string]T) T
+
+func New[T Number]() *Store[T] {
+	return &Store[T]{index: make(map[string]*T), trie: prefix_trie_chunked.New[*T]()}
+}
+
+// Len returns the number of keys
+func (s *Store[T]) Len() int {
+	return len(s.index)
+}
+
+func (s *Store[T]) Insert(key string, value T) {
+	if cell, ok := s.index[key]; ok {
+		// the trie points at the same cell, so there's nothing else to update
+		*cell = value
+		return
+	}
+	cell := new(T)
+	*cell = value
+	s.index[key] = cell
+	s.trie.Insert(key, cell)
+}
+
+// Delete removes key from the store and reports whether it was there
+func (s *Store[T]) Delete(key string) bool {
+	if _, ok := s.index[key]; !ok {
+		return false
+	}
+	delete(s.index, key)
+	s.trie.Delete(key)
+	return true
+}
+
+func (s *Store[T]) Search(key string) (bool, T) {
+	var defaultResult T
+	cell, ok := s.index[key]
+	if !ok {
+		return false, defaultResult
+	}
+	return true, *cell
+}
+
+// SearchPrefix returns all keys under prefix, mapped to their values.
+// This is answered by the trie, so prefixes work the way they do in prefix_trie_chunked: by whole chunks.
+// "profits.revenue" matches "profits.revenue.net", but "profits.rev" doesn't (mapkeys.Store would match it,
+// but that needs a scan over every sibling chunk, which is exactly what this store is trying to avoid).
+func (s *Store[T]) SearchPrefix(prefix string) map[string]T {
+	results := make(map[string]T)
+	if len(prefix) == 0 {
+		return results
+	}
+	s.trie.Walk(prefix, func(key []byte, cell *T, hasValue bool) prefix_trie_chunked.WalkAction {
+		if hasValue {
+			results[string(key)] = *cell
+		}
+		return prefix_trie_chunked.Continue
+	})
+	return results
+}
+
+// Each calls fn for every key in the trie's order (chunk by chunk, see prefix_trie_chunked.CompareKeys),
+// stopping early if fn returns false
+func (s *Store[T]) Each(fn func(key string, val T) bool) {
+	s.trie.Each(func(key string, cell *T) bool {
+		return fn(key, *cell)
+	})
+}
+
+// AggregateDescendants aggregates values for all keys with a certain prefix.
+// It returns a bool indicating whether or not the result is valid (or just a meaningless float64 zero value), and a float64
+func (s *Store[T]) AggregateDescendants(prefix string, aggFunc AggregationFunction[T]) (bool, float64) {
+	// get descendants with a prefix search
+	descendants := s.SearchPrefix(prefix)
+	if len(descendants) == 0 {
+		return false, 0
+	}
+
+	// apply aggregation function
+	return true, float64(aggFunc(descendants))
+}
+
+func Sum[T Number](keysAndVals map[string]T) T {
+	var sum T
+	for _, v := range keysAndVals {
+		sum += v
+	}
+	return sum
+}
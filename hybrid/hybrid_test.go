@@ -0,0 +1,108 @@
+package hybrid
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/groovemonkey/trie-keys-experiment/prefix_trie_chunked"
+)
+
+func TestInsertSearchDelete(t *testing.T) {
+	store := New[int64]()
+	store.Insert("profits", 1)
+	store.Insert("profits.revenue", 2)
+	store.Insert("profits", 10)
+
+	if found, val := store.Search("profits"); !found || val != 10 {
+		t.Errorf("expected replacements to work, found=%v, val=%d", found, val)
+	}
+	// the overwrite is visible through the trie too
+	if results := store.SearchPrefix("profits"); results["profits"] != 10 {
+		t.Errorf("expected the prefix search to see the new value, got %v", results)
+	}
+
+	if !store.Delete("profits") || store.Delete("profits") {
+		t.Errorf("expected only the first delete to succeed")
+	}
+	if found, _ := store.Search("profits"); found {
+		t.Errorf("expected profits to be gone")
+	}
+	expected := map[string]int64{"profits.revenue": 2}
+	if results := store.SearchPrefix("profits"); !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected %v after the delete, got %v", expected, results)
+	}
+	if store.Len() != 1 {
+		t.Errorf("expected 1 key, got %d", store.Len())
+	}
+}
+
+func TestSearchPrefix(t *testing.T) {
+	store := New[int64]()
+	store.Insert("business_summary.departments.finance", 0)
+	store.Insert("business_summary.departments.software", 100)
+	store.Insert("business_summary.revenue.top_line", 70)
+	store.Insert("business_summary.revenue.net", 50)
+	store.Insert("business_summaryz", 1)
+	store.Insert("profits", 3)
+
+	for prefix, expected := range map[string]map[string]int64{
+		"business_summary.revenue": {"business_summary.revenue.top_line": 70, "business_summary.revenue.net": 50},
+		"business_summary":         {"business_summary.departments.finance": 0, "business_summary.departments.software": 100, "business_summary.revenue.top_line": 70, "business_summary.revenue.net": 50},
+		"business_summaryz":        {"business_summaryz": 1},
+		// prefixes match whole chunks only, like in the chunked trie
+		"business_summary.rev": {},
+		"prof":                 {},
+		"nope.nope":            {},
+	} {
+		if results := store.SearchPrefix(prefix); !reflect.DeepEqual(results, expected) {
+			t.Errorf("expected %q to find %v, got %v", prefix, expected, results)
+		}
+	}
+
+	found, sum := store.AggregateDescendants("business_summary.departments", Sum[int64])
+	if !found || sum != 100 {
+		t.Errorf("expected departments to sum to 100, found=%v, sum=%f", found, sum)
+	}
+}
+
+func TestEach(t *testing.T) {
+	store := New[int64]()
+	store.Insert("a-b", 1)
+	store.Insert("a.b", 2)
+	store.Insert("a", 3)
+	store.Insert("a.b", 4)
+	var keys []string
+	var vals []int64
+	store.Each(func(key string, val int64) bool {
+		keys = append(keys, key)
+		vals = append(vals, val)
+		return true
+	})
+	// chunk by chunk, like the trie
+	if expected := []string{"a", "a.b", "a-b"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %v, got %v", expected, keys)
+	}
+	if expected := []int64{3, 4, 1}; !reflect.DeepEqual(vals, expected) {
+		t.Errorf("expected %v, got %v", expected, vals)
+	}
+}
+
+func TestStats(t *testing.T) {
+	store := New[int64]()
+	trie := prefix_trie_chunked.New[*int64]()
+	for _, key := range []string{"profits.revenue", "profits.revenue.net", "losses.revenue"} {
+		store.Insert(key, 1)
+		trie.Insert(key, nil)
+	}
+	stats, trieStats := store.Stats(), trie.Stats()
+	if stats.Keys != 3 || stats.Nodes != trieStats.Nodes || stats.MaxDepth != 3 || !reflect.DeepEqual(stats.Fanout, trieStats.Fanout) {
+		t.Errorf("expected the trie's shape, got %+v", stats)
+	}
+	// the map adds its keys, its table and the value cells on top of the trie
+	if stats.StringBytes != trieStats.StringBytes+len("profits.revenue")+len("profits.revenue.net")+len("losses.revenue") {
+		t.Errorf("expected the map's keys to be counted, got %d string bytes", stats.StringBytes)
+	}
+	if stats.ChildrenBytes <= trieStats.ChildrenBytes || stats.ValueBytes <= trieStats.ValueBytes {
+		t.Errorf("expected the map to be counted, got %+v", stats)
+	}
+}
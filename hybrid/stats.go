@@ -0,0 +1,22 @@
+package hybrid
+
+import (
+	"unsafe"
+
+	"github.com/groovemonkey/trie-keys-experiment/stats"
+)
+
+// Stats returns the store's Stats: the trie's shape, with the map added to the bytes.
+// Value bytes are the shared value cells plus the pointers to them in the map and the trie's nodes.
+func (s *Store[T]) Stats() stats.Stats {
+	var zero T
+	st := s.trie.Stats()
+	pointerSize := int(unsafe.Sizeof(&zero))
+	for key := range s.index {
+		st.StringBytes += len(key)
+	}
+	indexBytes := stats.MapBytes(len(s.index), unsafe.Sizeof("")+unsafe.Sizeof(&zero))
+	st.ChildrenBytes += indexBytes - len(s.index)*pointerSize
+	st.ValueBytes += len(s.index)*pointerSize + len(s.index)*int(unsafe.Sizeof(zero))
+	return st
+}
@@ -0,0 +1,130 @@
+package louds_trie
+
+import "math/bits"
+
+// bitVector is an append-only bit vector with rank and select support.
+// Ranks are precomputed once per 512-bit block, and the word holding every 256th 0 is sampled for select,
+// which together cost roughly 10% on top of the bits themselves.
+type bitVector struct {
+	words []uint64
+	// number of bits
+	length int
+	// blockRanks[i] is the number of 1s before block i (a block is 8 words)
+	blockRanks []uint32
+	// zeroSamples[i] is the word holding the (i*zerosPerSample+1)th 0, and how many 0s come before that word
+	zeroSamples []zeroSample
+}
+
+type zeroSample struct {
+	word        uint32
+	zerosBefore uint32
+}
+
+const (
+	wordsPerBlock  = 8
+	zerosPerSample = 256
+)
+
+func (bv *bitVector) push(bit bool) {
+	if bv.length%64 == 0 {
+		bv.words = append(bv.words, 0)
+	}
+	if bit {
+		bv.words[bv.length/64] |= 1 << (bv.length % 64)
+	}
+	bv.length++
+}
+
+// finish builds the rank directory, it must be called once all bits are pushed
+func (bv *bitVector) finish() {
+	bv.blockRanks = make([]uint32, 0, len(bv.words)/wordsPerBlock+1)
+	bv.zeroSamples = bv.zeroSamples[:0]
+	var ones, zeros uint32
+	for i, word := range bv.words {
+		if i%wordsPerBlock == 0 {
+			bv.blockRanks = append(bv.blockRanks, ones)
+		}
+		wordZeros := uint32(64 - bits.OnesCount64(word))
+		// sample every word that holds the next multiple-of-zerosPerSample 0
+		for uint32(len(bv.zeroSamples))*zerosPerSample < zeros+wordZeros {
+			bv.zeroSamples = append(bv.zeroSamples, zeroSample{word: uint32(i), zerosBefore: zeros})
+		}
+		ones += uint32(bits.OnesCount64(word))
+		zeros += wordZeros
+	}
+}
+
+func (bv *bitVector) get(i int) bool {
+	return bv.words[i/64]&(1<<(i%64)) != 0
+}
+
+// ones returns the number of 1s in the whole vector
+func (bv *bitVector) ones() int {
+	ones := 0
+	for _, word := range bv.words {
+		ones += bits.OnesCount64(word)
+	}
+	return ones
+}
+
+// rank1 returns the number of 1s in bits [0, i)
+func (bv *bitVector) rank1(i int) int {
+	word := i / 64
+	ones := int(bv.blockRanks[word/wordsPerBlock])
+	for w := word / wordsPerBlock * wordsPerBlock; w < word; w++ {
+		ones += bits.OnesCount64(bv.words[w])
+	}
+	if i%64 != 0 {
+		ones += bits.OnesCount64(bv.words[word] & (1<<(i%64) - 1))
+	}
+	return ones
+}
+
+// select0 returns the position of the jth 0 (counting from 1)
+func (bv *bitVector) select0(j int) int {
+	// start from the sampled word, which is at most a few words away
+	sample := bv.zeroSamples[(j-1)/zerosPerSample]
+	j -= int(sample.zerosBefore)
+	for w := int(sample.word); w < len(bv.words); w++ {
+		zeros := 64 - bits.OnesCount64(bv.words[w])
+		if j > zeros {
+			j -= zeros
+			continue
+		}
+		return w*64 + selectInWord(^bv.words[w], j)
+	}
+	return bv.length
+}
+
+// nextZero returns the position of the first 0 at or after i
+func (bv *bitVector) nextZero(i int) int {
+	w := i / 64
+	// pretend the bits before i are 1s
+	word := bv.words[w] | (1<<(i%64) - 1)
+	for word == ^uint64(0) {
+		w++
+		if w == len(bv.words) {
+			return bv.length
+		}
+		word = bv.words[w]
+	}
+	return w*64 + bits.TrailingZeros64(^word)
+}
+
+// selectInWord returns the position of the jth 1 in word (counting from 1), narrowing it down a byte at a time
+func selectInWord(word uint64, j int) int {
+	pos := 0
+	for {
+		ones := bits.OnesCount8(uint8(word))
+		if j <= ones {
+			break
+		}
+		j -= ones
+		word >>= 8
+		pos += 8
+	}
+	for ; j > 1; j-- {
+		word &= word - 1
+	}
+	return pos + bits.TrailingZeros64(word)
+}
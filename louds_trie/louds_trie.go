@@ -0,0 +1,368 @@
+package louds_trie
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+// A LOUDS (level-order unary degree sequence) trie stores the shape of a byte-level trie as a bit vector:
+// visiting nodes breadth first, each node writes a 1 per child followed by a 0. With rank and select on
+// those bits we can get from a node to its children without storing any pointers, so the whole structure
+// costs a couple of bits per node plus one byte for its label.
+//
+// Nodes are numbered in breadth-first order, the root is 0. Node k's children are the 1s between the (k+1)th
+// and (k+2)th 0, and the 1 at position p belongs to node rank1(p) (the leading "10" is a fake parent of the root).
+
+// Value is what a Trie can hold: anything fixed-size, so the trie can be written to a file as is
+type Value interface {
+	~int8 | ~int16 | ~int32 | ~int64 | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~float32 | ~float64
+}
+
+type Trie[T Value] struct {
+	louds bitVector
+	// labels[k-1] is the byte on the edge into node k
+	labels []byte
+	// isKey has a 1 for every node where a key ends
+	isKey bitVector
+	// values in breadth-first order of their nodes, node k's value is values[isKey.rank1(k)]
+	values []T
+}
+
+type KeyValue[T Value] struct {
+	Key   string
+	Value T
+}
+
+// Source is anything that can list its keys and values. Every store in this repo has that Each except the arena tries,
+// the frozen chunked trie and the LOUDS trie itself; an fst.FST only qualifies as a Source[uint64].
+type Source[T Value] interface {
+	Each(fn func(key string, val T) bool)
+}
+
+// FromStore builds a trie from everything in store
+func FromStore[T Value](store Source[T]) *Trie[T] {
+	var kvs []KeyValue[T]
+	store.Each(func(key string, val T) bool {
+		kvs = append(kvs, KeyValue[T]{Key: key, Value: val})
+		return true
+	})
+	return Build(kvs)
+}
+
+// Build builds a trie containing the given keys. If a key appears more than once, the last value wins.
+func Build[T Value](kvs []KeyValue[T]) *Trie[T] {
+	keys := make([]KeyValue[T], len(kvs))
+	copy(keys, kvs)
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].Key < keys[j].Key })
+	unique := keys[:0]
+	for i, kv := range keys {
+		if i+1 < len(keys) && keys[i+1].Key == kv.Key {
+			continue
+		}
+		unique = append(unique, kv)
+	}
+
+	t := &Trie[T]{}
+	t.louds.push(true)
+	t.louds.push(false)
+
+	// every node is a run of keys sharing their first depth bytes
+	type group struct{ lo, hi, depth int }
+	queue := []group{{0, len(unique), 0}}
+	for len(queue) > 0 {
+		g := queue[0]
+		queue = queue[1:]
+
+		lo := g.lo
+		// sorting puts the key that ends here (if any) first
+		if lo < g.hi && len(unique[lo].Key) == g.depth {
+			t.isKey.push(true)
+			t.values = append(t.values, unique[lo].Value)
+			lo++
+		} else {
+			t.isKey.push(false)
+		}
+		// the rest are the children, grouped by their next byte
+		for lo < g.hi {
+			label := unique[lo].Key[g.depth]
+			hi := lo + 1
+			for hi < g.hi && unique[hi].Key[g.depth] == label {
+				hi++
+			}
+			t.louds.push(true)
+			t.labels = append(t.labels, label)
+			queue = append(queue, group{lo, hi, g.depth + 1})
+			lo = hi
+		}
+		t.louds.push(false)
+	}
+	t.louds.finish()
+	t.isKey.finish()
+	return t
+}
+
+// children returns the node number of node's first child and how many children it has
+func (t *Trie[T]) children(node int) (int, int) {
+	start := t.louds.select0(node+1) + 1
+	// the children end at the very next 0
+	end := t.louds.nextZero(start)
+	return t.louds.rank1(start), end - start
+}
+
+// child finds node's child with the given label
+func (t *Trie[T]) child(node int, label byte) (int, bool) {
+	first, n := t.children(node)
+	// siblings are sorted by label
+	labels := t.labels[first-1 : first-1+n]
+	i := sort.Search(n, func(i int) bool { return labels[i] >= label })
+	if i == n || labels[i] != label {
+		return 0, false
+	}
+	return first + i, true
+}
+
+func (t *Trie[T]) find(key string) (int, bool) {
+	node := 0
+	for i := 0; i < len(key); i++ {
+		var ok bool
+		if node, ok = t.child(node, key[i]); !ok {
+			return 0, false
+		}
+	}
+	return node, true
+}
+
+// Len returns the number of keys in the trie
+func (t *Trie[T]) Len() int {
+	return len(t.values)
+}
+
+// Search returns the value for key, and whether key exists
+func (t *Trie[T]) Search(key string) (bool, T) {
+	node, ok := t.find(key)
+	if !ok || !t.isKey.get(node) {
+		var zero T
+		return false, zero
+	}
+	return true, t.values[t.isKey.rank1(node)]
+}
+
+// SearchPrefix returns all Keys with the given prefix, mapped to their values
+func (t *Trie[T]) SearchPrefix(prefix string) map[string]T {
+	keysAndVals := make(map[string]T)
+	if len(prefix) == 0 {
+		return keysAndVals
+	}
+	node, ok := t.find(prefix)
+	if !ok {
+		return keysAndVals
+	}
+	t.collect(node, append(make([]byte, 0, len(prefix)+64), prefix...), keysAndVals)
+	return keysAndVals
+}
+
+// collect adds node and everything below it to keysAndVals, depth first
+func (t *Trie[T]) collect(node int, key []byte, keysAndVals map[string]T) []byte {
+	if t.isKey.get(node) {
+		keysAndVals[string(key)] = t.values[t.isKey.rank1(node)]
+	}
+	keyLen := len(key)
+	first, n := t.children(node)
+	for child := first; child < first+n; child++ {
+		key = t.collect(child, append(key[:keyLen], t.labels[child-1]), keysAndVals)
+	}
+	return key[:keyLen]
+}
+
+// the file format is this magic string, then the sections below in little endian
+var magic = [8]byte{'L', 'O', 'U', 'D', 'S', 'v', '1', 0}
+
+// ErrBadFormat is returned when reading something that isn't a serialized trie
+var ErrBadFormat = errors.New("not a LOUDS trie file")
+
+// WriteTo serializes the trie, see ReadFrom
+func (t *Trie[T]) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	cw := &countingWriter{w: bw}
+	sections := []any{
+		magic,
+		uint64(t.louds.length), t.louds.words,
+		uint64(len(t.labels)), t.labels,
+		uint64(t.isKey.length), t.isKey.words,
+		uint64(len(t.values)), t.values,
+	}
+	for _, section := range sections {
+		if err := binary.Write(cw, binary.LittleEndian, section); err != nil {
+			return cw.n, err
+		}
+	}
+	return cw.n, bw.Flush()
+}
+
+// ReadFrom reads a trie written by WriteTo. T has to be the same type it was written with.
+// A file that's cut short or whose sections don't describe a valid trie is an error wrapping ErrBadFormat,
+// rather than a trie that panics (or loops forever) when it's searched.
+func ReadFrom[T Value](r io.Reader) (*Trie[T], error) {
+	r = bufio.NewReader(r)
+	var header [8]byte
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, badFormat(err)
+	}
+	if header != magic {
+		return nil, ErrBadFormat
+	}
+
+	t := &Trie[T]{}
+	var err error
+	if t.louds, err = readBitVector(r); err != nil {
+		return nil, err
+	}
+	if t.labels, err = readSlice[byte](r); err != nil {
+		return nil, err
+	}
+	if t.isKey, err = readBitVector(r); err != nil {
+		return nil, err
+	}
+	if t.values, err = readSlice[T](r); err != nil {
+		return nil, err
+	}
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Save writes the trie to a file
+func (t *Trie[T]) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := t.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Open reads a trie from a file written by Save
+func Open[T Value](path string) (*Trie[T], error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadFrom[T](f)
+}
+
+// maxLength bounds every length in a file: ranks and select samples are uint32s, so no section can be longer than that
+const maxLength = math.MaxUint32
+
+// readChunk is how many elements readSlice reads at a time, so a corrupt length runs out of input long before
+// it allocates much more memory than the file actually holds
+const readChunk = 1 << 16
+
+func readBitVector(r io.Reader) (bitVector, error) {
+	var length uint64
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		return bitVector{}, badFormat(err)
+	}
+	if length > maxLength {
+		return bitVector{}, fmt.Errorf("%w: bit vector of %d bits", ErrBadFormat, length)
+	}
+	words, err := readElements[uint64](r, (length+63)/64)
+	if err != nil {
+		return bitVector{}, err
+	}
+	// Build leaves the bits past the end 0, and rank and select count whole words
+	if length%64 != 0 && words[len(words)-1]>>(length%64) != 0 {
+		return bitVector{}, fmt.Errorf("%w: bits set past the end of a bit vector", ErrBadFormat)
+	}
+	bv := bitVector{words: words, length: int(length)}
+	bv.finish()
+	return bv, nil
+}
+
+func readSlice[E any](r io.Reader) ([]E, error) {
+	var length uint64
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		return nil, badFormat(err)
+	}
+	if length > maxLength {
+		return nil, fmt.Errorf("%w: section of %d elements", ErrBadFormat, length)
+	}
+	return readElements[E](r, length)
+}
+
+// readElements reads n elements, a chunk at a time
+func readElements[E any](r io.Reader, n uint64) ([]E, error) {
+	s := make([]E, 0, min(n, readChunk))
+	for uint64(len(s)) < n {
+		chunk := int(min(n-uint64(len(s)), readChunk))
+		s = append(s, make([]E, chunk)...)
+		if err := binary.Read(r, binary.LittleEndian, s[len(s)-chunk:]); err != nil {
+			return nil, badFormat(err)
+		}
+	}
+	return s, nil
+}
+
+// badFormat turns running out of input into ErrBadFormat, and leaves other read errors alone
+func badFormat(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: file ends early", ErrBadFormat)
+	}
+	return err
+}
+
+// validate checks that the sections of a trie read from a file agree with each other, so that searching it
+// can't index out of range or loop
+func (t *Trie[T]) validate() error {
+	// a trie with n nodes has 1 + (n-1) ones (the fake parent's and one per child) and 1 + n zeros (the fake parent's
+	// and one per node), starting with the fake parent's "10"
+	n := t.louds.ones()
+	if n == 0 || t.louds.length != 2*n+1 || !t.louds.get(0) || t.louds.get(1) {
+		return fmt.Errorf("%w: %d of %d LOUDS bits are set", ErrBadFormat, n, t.louds.length)
+	}
+	if len(t.labels) != n-1 || t.isKey.length != n {
+		return fmt.Errorf("%w: %d nodes but %d labels and %d key bits", ErrBadFormat, n, len(t.labels), t.isKey.length)
+	}
+	if keys := t.isKey.ones(); len(t.values) != keys {
+		return fmt.Errorf("%w: %d keys but %d values", ErrBadFormat, keys, len(t.values))
+	}
+	// breadth-first order means every node is some earlier node's child: node k's 1 has to come before the 0 that
+	// starts its own children, so they're all numbered after it. Siblings also have to be in label order.
+	ones, zeros := 0, 0
+	for i := 0; i < t.louds.length; i++ {
+		if !t.louds.get(i) {
+			if zeros < n && ones <= zeros {
+				return fmt.Errorf("%w: node %d has children before it's a child itself", ErrBadFormat, zeros)
+			}
+			zeros++
+			continue
+		}
+		ones++
+		// the 1 for node ones-1, whose label is labels[ones-2]; the root has no label
+		if ones >= 3 && t.louds.get(i-1) && t.labels[ones-3] >= t.labels[ones-2] {
+			return fmt.Errorf("%w: children of node %d aren't sorted by label", ErrBadFormat, zeros-1)
+		}
+	}
+	return nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,180 @@
+package louds_trie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/groovemonkey/trie-keys-experiment/double_array_trie"
+	"github.com/groovemonkey/trie-keys-experiment/mapkeys"
+	"github.com/groovemonkey/trie-keys-experiment/prefix_trie_chunked"
+)
+
+func TestSearch(t *testing.T) {
+	trie := Build([]KeyValue[int64]{
+		{"business_summary.departments.finance", 0},
+		{"business_summary.departments.software", 100},
+		{"héllo", 7},
+		{"a", 1},
+		{"aa", 2},
+		{"aa", 3},
+		{"", 4},
+	})
+
+	if trie.Len() != 6 {
+		t.Errorf("expected duplicate keys to be merged into 6 keys, got %d", trie.Len())
+	}
+	for key, expected := range map[string]int64{"a": 1, "aa": 3, "héllo": 7, "": 4, "business_summary.departments.software": 100} {
+		if found, val := trie.Search(key); !found || val != expected {
+			t.Errorf("expected %q to be %d, got %v %d", key, expected, found, val)
+		}
+	}
+	for _, key := range []string{"aaa", "b", "business_summary", "hé"} {
+		if found, _ := trie.Search(key); found {
+			t.Errorf("expected %q not to be found", key)
+		}
+	}
+}
+
+func TestFromStore(t *testing.T) {
+	store := prefix_trie_chunked.New[int64]()
+	store.Insert("profits.revenue", 2)
+	store.Insert("profits.revenue.net", 3)
+	store.Insert("profits.revenue.taxes", -200)
+	store.Insert("profits-2023", 1)
+
+	trie := FromStore[int64](store)
+	results := trie.SearchPrefix("profits.revenue")
+	expected := map[string]int64{"profits.revenue": 2, "profits.revenue.net": 3, "profits.revenue.taxes": -200}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected %v, got %v", expected, results)
+	}
+
+	mapStore := make(mapkeys.Store[int64])
+	mapStore.Insert("foo", 1)
+	if found, val := FromStore[int64](&mapStore).Search("foo"); !found || val != 1 {
+		t.Errorf("expected to build from a map store")
+	}
+	dat := double_array_trie.Build([]double_array_trie.KeyValue[int64]{{Key: "foo", Value: 2}})
+	if found, val := FromStore[int64](dat).Search("foo"); !found || val != 2 {
+		t.Errorf("expected to build from a double-array trie")
+	}
+}
+
+func TestSerialization(t *testing.T) {
+	kvs := make([]KeyValue[float64], 0, 2000)
+	for i := 0; i < 2000; i++ {
+		kvs = append(kvs, KeyValue[float64]{Key: string(rune('a'+i%26)) + "." + string(rune('a'+i/26%26)) + string(rune('A'+i/676)), Value: float64(i) / 2})
+	}
+	trie := Build(kvs)
+
+	var buf bytes.Buffer
+	n, err := trie.WriteTo(&buf)
+	if err != nil || n != int64(buf.Len()) {
+		t.Fatalf("expected WriteTo to write %d bytes, got %d (%v)", buf.Len(), n, err)
+	}
+	read, err := ReadFrom[float64](&buf)
+	if err != nil {
+		t.Fatalf("expected to read the trie back, got %v", err)
+	}
+	for _, kv := range kvs {
+		if found, val := read.Search(kv.Key); !found || val != kv.Value {
+			t.Errorf("expected %q to be %v after a round trip, got %v %v", kv.Key, kv.Value, found, val)
+		}
+	}
+	if !reflect.DeepEqual(read.SearchPrefix("a"), trie.SearchPrefix("a")) {
+		t.Errorf("expected prefix searches to match after a round trip")
+	}
+
+	path := filepath.Join(t.TempDir(), "keys.louds")
+	if err := trie.Save(path); err != nil {
+		t.Fatalf("expected Save to work, got %v", err)
+	}
+	opened, err := Open[float64](path)
+	if err != nil || opened.Len() != trie.Len() {
+		t.Errorf("expected Open to read %d keys, got %v", trie.Len(), err)
+	}
+
+	if _, err := ReadFrom[float64](bytes.NewReader([]byte("definitely not a trie"))); err != ErrBadFormat {
+		t.Errorf("expected ErrBadFormat, got %v", err)
+	}
+}
+
+func TestCorruptFile(t *testing.T) {
+	trie := Build([]KeyValue[int64]{{"ab", 1}, {"abc", 2}, {"ad", 3}, {"b", 4}})
+	var buf bytes.Buffer
+	trie.WriteTo(&buf)
+	file := buf.Bytes()
+
+	// sections start after the 8 byte magic: louds length and words, labels length and bytes, isKey length and words
+	loudsLength := 8
+	labelsLength := loudsLength + 8 + 8
+	withUint64 := func(offset int, v uint64) []byte {
+		corrupt := bytes.Clone(file)
+		binary.LittleEndian.PutUint64(corrupt[offset:], v)
+		return corrupt
+	}
+	withLabels := func(labels string) []byte {
+		corrupt := bytes.Clone(file)
+		copy(corrupt[labelsLength+8:], labels)
+		return corrupt
+	}
+	for name, corrupt := range map[string][]byte{
+		"cut short":              file[:len(file)-1],
+		"huge section":           withUint64(labelsLength, 1<<40),
+		"huge section in range":  withUint64(labelsLength, 1<<31),
+		"huge bit vector":        withUint64(loudsLength, 1<<62),
+		"too few labels":         append(withUint64(labelsLength, 4)[:labelsLength+8+4], file[labelsLength+8+5:]...),
+		"louds bits past length": withUint64(loudsLength+8, 1<<20|0b10),
+		"unsorted siblings":      withLabels("bacd"),
+		// the root has no children, but nodes 1 to 5 come after it
+		"child before parent": withUint64(loudsLength+8, 0b0_0000_1111_1001),
+		"too many values":     append(withUint64(len(file)-8*5, 5), make([]byte, 8)...),
+	} {
+		if _, err := ReadFrom[int64](bytes.NewReader(corrupt)); !errors.Is(err, ErrBadFormat) {
+			t.Errorf("%s: expected ErrBadFormat, got %v", name, err)
+		}
+	}
+
+	// whatever gets through has to be searchable without panicking
+	for i := 8; i < len(file); i++ {
+		for bit := 0; bit < 8; bit++ {
+			corrupt := bytes.Clone(file)
+			corrupt[i] ^= 1 << bit
+			read, err := ReadFrom[int64](bytes.NewReader(corrupt))
+			if err != nil {
+				continue
+			}
+			for _, key := range []string{"", "a", "ab", "abc", "ad", "b", "z"} {
+				read.Search(key)
+				read.SearchPrefix(key)
+			}
+		}
+	}
+	for i := 0; i < len(file); i++ {
+		if _, err := ReadFrom[int64](bytes.NewReader(file[:i])); !errors.Is(err, ErrBadFormat) {
+			t.Errorf("expected ErrBadFormat for the first %d bytes, got %v", i, err)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	trie := Build([]KeyValue[int64]{{"ab", 1}, {"abc", 2}, {"ad", 3}})
+	stats := trie.Stats()
+	if stats.Keys != 3 || stats.Nodes != 5 || stats.ValuelessNodes != 1 {
+		t.Errorf("expected 3 keys in 5 nodes with 1 valueless one, got %+v", stats)
+	}
+	if stats.MaxDepth != 3 || stats.AvgDepth != 7.0/3 {
+		t.Errorf("expected depths of 3 and 7/3, got %d and %v", stats.MaxDepth, stats.AvgDepth)
+	}
+	expected := map[int]int{0: 2, 1: 2, 2: 1}
+	if !reflect.DeepEqual(stats.Fanout, expected) {
+		t.Errorf("expected fanout %v, got %v", expected, stats.Fanout)
+	}
+	if stats.NodeBytes < 4 || stats.ChildrenBytes <= 0 || stats.ValueBytes < 3*8 {
+		t.Errorf("unexpected bytes %+v", stats)
+	}
+}
@@ -0,0 +1,34 @@
+package louds_trie
+
+import (
+	"unsafe"
+
+	"github.com/groovemonkey/trie-keys-experiment/stats"
+)
+
+// Stats walks the whole trie and returns its Stats.
+// Node bytes are the labels and the is-key bits, and children bytes the LOUDS bits with their rank and select directories.
+func (t *Trie[T]) Stats() stats.Stats {
+	st := stats.New()
+	var visit func(node, depth int)
+	visit = func(node, depth int) {
+		first, n := t.children(node)
+		st.AddNode(depth, n, t.isKey.get(node))
+		for child := first; child < first+n; child++ {
+			visit(child, depth+1)
+		}
+	}
+	visit(0, 0)
+	st.Finish()
+	st.NodeBytes = cap(t.labels) + t.isKey.bytes()
+	st.ChildrenBytes = t.louds.bytes()
+	st.ValueBytes = cap(t.values) * int(unsafe.Sizeof(T(0)))
+	return st
+}
+
+// bytes is the memory taken by the bits and the rank and select directories
+func (bv *bitVector) bytes() int {
+	return cap(bv.words)*int(unsafe.Sizeof(uint64(0))) +
+		cap(bv.blockRanks)*int(unsafe.Sizeof(uint32(0))) +
+		cap(bv.zeroSamples)*int(unsafe.Sizeof(zeroSample{}))
+}
@@ -1,12 +1,32 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"maps"
 	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
-
+	"time"
+
+	"github.com/groovemonkey/trie-keys-experiment/bplus_tree"
+	"github.com/groovemonkey/trie-keys-experiment/burst_trie"
+	"github.com/groovemonkey/trie-keys-experiment/double_array_trie"
+	"github.com/groovemonkey/trie-keys-experiment/fst"
+	"github.com/groovemonkey/trie-keys-experiment/hamt"
+	"github.com/groovemonkey/trie-keys-experiment/hybrid"
+	"github.com/groovemonkey/trie-keys-experiment/louds_trie"
 	"github.com/groovemonkey/trie-keys-experiment/mapkeys"
 	"github.com/groovemonkey/trie-keys-experiment/prefix_trie"
 	"github.com/groovemonkey/trie-keys-experiment/prefix_trie_chunked"
+	"github.com/groovemonkey/trie-keys-experiment/skip_list"
+	"github.com/groovemonkey/trie-keys-experiment/sorted_array"
+	"github.com/groovemonkey/trie-keys-experiment/ternary_search_tree"
+	"github.com/groovemonkey/trie-keys-experiment/workload"
 )
 
 const letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ:."
@@ -214,10 +234,110 @@ func BenchmarkSearchPrefixMapRealistic(b *testing.B) {
 }
 
 // /////////////////
-// // Trie (single-character)
+// // HAMT
 // /////////////////
-func BenchmarkInsertTrieRandom(b *testing.B) {
-	store := prefix_trie.New[int]()
+func BenchmarkInsertHAMTRandom(b *testing.B) {
+	data := makeRandomDataMap(b.N)
+	store := hamt.New[int]()
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		store = store.Insert(data[i], i)
+	}
+}
+
+func BenchmarkInsertHAMTRealistic(b *testing.B) {
+	store := hamt.New[int]()
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key, val := range realisticBenchmarkData {
+			store = store.Insert(key, val)
+		}
+	}
+}
+
+func BenchmarkSearchHAMTRandom(b *testing.B) {
+	store := hamt.New[int]()
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	// insert into store
+	for val, key := range data {
+		store = store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// The function we're testing
+		store.Search(data[i])
+	}
+}
+
+func BenchmarkSearchHAMTRealistic(b *testing.B) {
+	store := hamt.New[int]()
+
+	// insert into store
+	for key, val := range realisticBenchmarkData {
+		store = store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key := range realisticBenchmarkData {
+			// The function we're testing
+			store.Search(key)
+		}
+	}
+}
+
+// Taking a snapshot of a map means copying it; a HAMT version is already a snapshot, so it's just keeping the pointer.
+// Both benchmarks take a snapshot and then write to the live store, like a reader holding on to a consistent view would.
+func BenchmarkSnapshotMapRealistic(b *testing.B) {
+	store := make(mapkeys.Store[int])
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		snapshot := maps.Clone(store)
+		store.Insert("profits", i)
+		_ = snapshot
+	}
+}
+
+func BenchmarkSnapshotHAMTRealistic(b *testing.B) {
+	store := hamt.New[int]()
+	for key, val := range realisticBenchmarkData {
+		store = store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		snapshot := store
+		store = store.Insert("profits", i)
+		_ = snapshot
+	}
+}
+
+// /////////////////
+// // Sorted array
+// /////////////////
+func BenchmarkInsertSortedArrayRandom(b *testing.B) {
+	store := sorted_array.New[int]()
 
 	// make a slice of test case strings, just long enough for all benchmark runs to complete
 	data := makeRandomDataMap(b.N)
@@ -230,8 +350,8 @@ func BenchmarkInsertTrieRandom(b *testing.B) {
 	}
 }
 
-func BenchmarkInsertTrieRealistic(b *testing.B) {
-	store := prefix_trie.New[int]()
+func BenchmarkInsertSortedArrayRealistic(b *testing.B) {
+	store := sorted_array.New[int]()
 
 	// Setup complete, let's bench
 	b.ResetTimer()
@@ -243,8 +363,8 @@ func BenchmarkInsertTrieRealistic(b *testing.B) {
 	}
 }
 
-func BenchmarkSearchTrieRandom(b *testing.B) {
-	store := prefix_trie.New[int]()
+func BenchmarkSearchSortedArrayRandom(b *testing.B) {
+	store := sorted_array.New[int]()
 
 	// make a slice of test case strings, just long enough for all benchmark runs to complete
 	data := makeRandomDataMap(b.N)
@@ -263,8 +383,8 @@ func BenchmarkSearchTrieRandom(b *testing.B) {
 	}
 }
 
-func BenchmarkSearchTrieRealistic(b *testing.B) {
-	store := prefix_trie.New[int]()
+func BenchmarkSearchSortedArrayRealistic(b *testing.B) {
+	store := sorted_array.New[int]()
 
 	// insert into store
 	for key, val := range realisticBenchmarkData {
@@ -275,7 +395,7 @@ func BenchmarkSearchTrieRealistic(b *testing.B) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		for key, _ := range realisticBenchmarkData {
+		for key := range realisticBenchmarkData {
 			// The function we're testing
 			store.Search(key)
 		}
@@ -283,8 +403,8 @@ func BenchmarkSearchTrieRealistic(b *testing.B) {
 
 }
 
-func BenchmarkSearchPrefixTrieRandom(b *testing.B) {
-	store := prefix_trie.New[int]()
+func BenchmarkSearchPrefixSortedArrayRandom(b *testing.B) {
+	store := sorted_array.New[int]()
 
 	// make a slice of test case strings, just long enough for all benchmark runs to complete
 	data := makeRandomDataMap(b.N)
@@ -311,8 +431,8 @@ func BenchmarkSearchPrefixTrieRandom(b *testing.B) {
 	}
 }
 
-func BenchmarkSearchPrefixTrieRealistic(b *testing.B) {
-	store := prefix_trie.New[int]()
+func BenchmarkSearchPrefixSortedArrayRealistic(b *testing.B) {
+	store := sorted_array.New[int]()
 
 	// insert into store
 	for key, val := range realisticBenchmarkData {
@@ -341,10 +461,10 @@ func BenchmarkSearchPrefixTrieRealistic(b *testing.B) {
 }
 
 // /////////////////
-// // Trie Chunked
+// // Trie (single-character)
 // /////////////////
-func BenchmarkInsertTrieChunkedRandom(b *testing.B) {
-	store := prefix_trie_chunked.New[int]()
+func BenchmarkInsertTrieRandom(b *testing.B) {
+	store := prefix_trie.New[int]()
 
 	// make a slice of test case strings, just long enough for all benchmark runs to complete
 	data := makeRandomDataMap(b.N)
@@ -357,8 +477,8 @@ func BenchmarkInsertTrieChunkedRandom(b *testing.B) {
 	}
 }
 
-func BenchmarkInsertTrieChunkedRealistic(b *testing.B) {
-	store := prefix_trie_chunked.New[int]()
+func BenchmarkInsertTrieRealistic(b *testing.B) {
+	store := prefix_trie.New[int]()
 
 	// Setup complete, let's bench
 	b.ResetTimer()
@@ -370,8 +490,8 @@ func BenchmarkInsertTrieChunkedRealistic(b *testing.B) {
 	}
 }
 
-func BenchmarkSearchTrieChunkedRandom(b *testing.B) {
-	store := prefix_trie_chunked.New[int]()
+func BenchmarkSearchTrieRandom(b *testing.B) {
+	store := prefix_trie.New[int]()
 
 	// make a slice of test case strings, just long enough for all benchmark runs to complete
 	data := makeRandomDataMap(b.N)
@@ -390,8 +510,8 @@ func BenchmarkSearchTrieChunkedRandom(b *testing.B) {
 	}
 }
 
-func BenchmarkSearchTrieChunkedRealistic(b *testing.B) {
-	store := prefix_trie_chunked.New[int]()
+func BenchmarkSearchTrieRealistic(b *testing.B) {
+	store := prefix_trie.New[int]()
 
 	// insert into store
 	for key, val := range realisticBenchmarkData {
@@ -410,8 +530,8 @@ func BenchmarkSearchTrieChunkedRealistic(b *testing.B) {
 
 }
 
-func BenchmarkSearchPrefixTrieChunkedRandom(b *testing.B) {
-	store := prefix_trie_chunked.New[int]()
+func BenchmarkSearchPrefixTrieRandom(b *testing.B) {
+	store := prefix_trie.New[int]()
 
 	// make a slice of test case strings, just long enough for all benchmark runs to complete
 	data := makeRandomDataMap(b.N)
@@ -438,8 +558,8 @@ func BenchmarkSearchPrefixTrieChunkedRandom(b *testing.B) {
 	}
 }
 
-func BenchmarkSearchPrefixTrieChunkedRealistic(b *testing.B) {
-	store := prefix_trie_chunked.New[int]()
+func BenchmarkSearchPrefixTrieRealistic(b *testing.B) {
+	store := prefix_trie.New[int]()
 
 	// insert into store
 	for key, val := range realisticBenchmarkData {
@@ -466,3 +586,2241 @@ func BenchmarkSearchPrefixTrieChunkedRealistic(b *testing.B) {
 		store.SearchPrefix("testing.very.long.string.keys.with.many.many.many.many.segments.jhsdkfjhskdjhfks.kjhsdkjfhskdjhfksjhdf.kjshdkhskdjhfksjdhfkjshdfkjh.kjhsdkfjhskdjfhksjhdf.sd.sdf.sdf.sdf.sd.fs.dfs.dfs.dfs.df")
 	}
 }
+
+func BenchmarkWalkTrieRealistic(b *testing.B) {
+	store := prefix_trie.New[int]()
+
+	// insert into store
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+
+	// count the keys we see, the same work SearchPrefix does minus building the result map
+	var keys int
+	visitor := func(key []byte, val int, hasValue bool) prefix_trie.WalkAction {
+		if hasValue {
+			keys++
+		}
+		return prefix_trie.Continue
+	}
+
+	// Setup complete, let's bench
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// short keys
+		store.Walk("business_revenue", visitor)
+
+		// medium keys
+		store.Walk("profits", visitor)
+
+		// long keys
+		store.Walk("testing", visitor)
+
+		// half of a medium key
+		store.Walk("profits.revenue.top_line", visitor)
+
+		// half of a wildly long key
+		store.Walk("testing.very.long.string.keys.with.many.many.many.many.segments.jhsdkfjhskdjhfks.kjhsdkjfhskdjhfksjhdf.kjshdkhskdjhfksjdhfkjshdfkjh.kjhsdkfjhskdjfhksjhdf.sd.sdf.sdf.sdf.sd.fs.dfs.dfs.dfs.df", visitor)
+	}
+}
+
+// sortedTrieData returns the data in the order prefix_trie.NewFromSortedSlice wants
+func sortedTrieData(data map[string]int) []prefix_trie.KeyValue[int] {
+	kvs := make([]prefix_trie.KeyValue[int], 0, len(data))
+	for key, val := range data {
+		kvs = append(kvs, prefix_trie.KeyValue[int]{Key: key, Value: val})
+	}
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+	return kvs
+}
+
+// building a whole trie per op, one Insert at a time (compare with BenchmarkBuildSortedTrie*)
+func BenchmarkBuildInsertTrieRealistic(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		store := prefix_trie.New[int]()
+		for key, val := range realisticBenchmarkData {
+			store.Insert(key, val)
+		}
+	}
+}
+
+func BenchmarkBuildSortedTrieRealistic(b *testing.B) {
+	data := sortedTrieData(realisticBenchmarkData)
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		prefix_trie.NewFromSortedSlice(data)
+	}
+}
+
+func BenchmarkBuildInsertTrieRandom(b *testing.B) {
+	data := make(map[string]int)
+	for val, key := range makeRandomDataMap(1000) {
+		data[key] = val
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		store := prefix_trie.New[int]()
+		for key, val := range data {
+			store.Insert(key, val)
+		}
+	}
+}
+
+func BenchmarkBuildSortedTrieRandom(b *testing.B) {
+	data := make(map[string]int)
+	for val, key := range makeRandomDataMap(1000) {
+		data[key] = val
+	}
+	sorted := sortedTrieData(data)
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		prefix_trie.NewFromSortedSlice(sorted)
+	}
+}
+
+// /////////////////
+// // B+tree
+// /////////////////
+func BenchmarkInsertBPlusTreeRandom(b *testing.B) {
+	store := bplus_tree.New[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		store.Insert(data[i], i)
+	}
+}
+
+func BenchmarkInsertBPlusTreeRealistic(b *testing.B) {
+	store := bplus_tree.New[int]()
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key, val := range realisticBenchmarkData {
+			store.Insert(key, val)
+		}
+	}
+}
+
+func BenchmarkSearchBPlusTreeRandom(b *testing.B) {
+	store := bplus_tree.New[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	for val, key := range data {
+		// insert into store
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// The function we're testing
+		store.Search(data[i])
+	}
+}
+
+func BenchmarkSearchBPlusTreeRealistic(b *testing.B) {
+	store := bplus_tree.New[int]()
+
+	// insert into store
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key := range realisticBenchmarkData {
+			// The function we're testing
+			store.Search(key)
+		}
+	}
+
+}
+
+func BenchmarkSearchPrefixBPlusTreeRandom(b *testing.B) {
+	store := bplus_tree.New[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	for val, key := range data {
+		// insert into store
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	var testString string
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		// chop the string in half
+		testString = data[i]
+		half := testString[:(len(testString) / 2)]
+		testString = half
+		b.StartTimer()
+
+		// The function we're testing
+		store.SearchPrefix(testString)
+	}
+}
+
+func BenchmarkSearchPrefixBPlusTreeRealistic(b *testing.B) {
+	store := bplus_tree.New[int]()
+
+	// insert into store
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// short keys
+		store.SearchPrefix("business_revenue")
+
+		// medium keys
+		store.SearchPrefix("profits")
+
+		// long keys
+		store.SearchPrefix("testing")
+
+		// half of a medium key
+		store.SearchPrefix("profits.revenue.top_line")
+
+		// half of a wildly long key
+		store.SearchPrefix("testing.very.long.string.keys.with.many.many.many.many.segments.jhsdkfjhskdjhfks.kjhsdkjfhskdjhfksjhdf.kjshdkhskdjhfksjdhfkjshdfkjh.kjhsdkfjhskdjfhksjhdf.sd.sdf.sdf.sdf.sd.fs.dfs.dfs.dfs.df")
+	}
+}
+
+// /////////////////
+// // Skip list
+// /////////////////
+func BenchmarkInsertSkipListRandom(b *testing.B) {
+	store := skip_list.New[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		store.Insert(data[i], i)
+	}
+}
+
+func BenchmarkInsertSkipListRealistic(b *testing.B) {
+	store := skip_list.New[int]()
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key, val := range realisticBenchmarkData {
+			store.Insert(key, val)
+		}
+	}
+}
+
+func BenchmarkSearchSkipListRandom(b *testing.B) {
+	store := skip_list.New[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	for val, key := range data {
+		// insert into store
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// The function we're testing
+		store.Search(data[i])
+	}
+}
+
+func BenchmarkSearchSkipListRealistic(b *testing.B) {
+	store := skip_list.New[int]()
+
+	// insert into store
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key := range realisticBenchmarkData {
+			// The function we're testing
+			store.Search(key)
+		}
+	}
+
+}
+
+func BenchmarkSearchPrefixSkipListRandom(b *testing.B) {
+	store := skip_list.New[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	for val, key := range data {
+		// insert into store
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	var testString string
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		// chop the string in half
+		testString = data[i]
+		half := testString[:(len(testString) / 2)]
+		testString = half
+		b.StartTimer()
+
+		// The function we're testing
+		store.SearchPrefix(testString)
+	}
+}
+
+func BenchmarkSearchPrefixSkipListRealistic(b *testing.B) {
+	store := skip_list.New[int]()
+
+	// insert into store
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// short keys
+		store.SearchPrefix("business_revenue")
+
+		// medium keys
+		store.SearchPrefix("profits")
+
+		// long keys
+		store.SearchPrefix("testing")
+
+		// half of a medium key
+		store.SearchPrefix("profits.revenue.top_line")
+
+		// half of a wildly long key
+		store.SearchPrefix("testing.very.long.string.keys.with.many.many.many.many.segments.jhsdkfjhskdjhfks.kjhsdkjfhskdjhfksjhdf.kjshdkhskdjhfksjdhfkjshdfkjh.kjhsdkfjhskdjfhksjhdf.sd.sdf.sdf.sdf.sd.fs.dfs.dfs.dfs.df")
+	}
+}
+
+// Parallel benchmarks: every goroutine hammers the same store.
+// The map and the chunked trie aren't safe for concurrent use, so they get a global RWMutex, which is what the skip list avoids.
+func parallelBenchmarkKeys() []string {
+	keys := make([]string, 0, 100000)
+	for i := 0; i < cap(keys); i++ {
+		keys = append(keys, RandStringBytes(randInt(5, 50)))
+	}
+	return keys
+}
+
+func BenchmarkInsertSkipListParallel(b *testing.B) {
+	store := skip_list.New[int]()
+	keys := parallelBenchmarkKeys()
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := rand.Intn(len(keys))
+		for pb.Next() {
+			store.Insert(keys[i%len(keys)], i)
+			i++
+		}
+	})
+}
+
+func BenchmarkInsertLockedMapParallel(b *testing.B) {
+	store := make(mapkeys.Store[int])
+	var mu sync.RWMutex
+	keys := parallelBenchmarkKeys()
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := rand.Intn(len(keys))
+		for pb.Next() {
+			mu.Lock()
+			store.Insert(keys[i%len(keys)], i)
+			mu.Unlock()
+			i++
+		}
+	})
+}
+
+func BenchmarkInsertLockedTrieChunkedParallel(b *testing.B) {
+	store := prefix_trie_chunked.New[int]()
+	var mu sync.RWMutex
+	keys := parallelBenchmarkKeys()
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := rand.Intn(len(keys))
+		for pb.Next() {
+			mu.Lock()
+			store.Insert(keys[i%len(keys)], i)
+			mu.Unlock()
+			i++
+		}
+	})
+}
+
+// 90% searches, 10% inserts
+func BenchmarkMixedSkipListParallel(b *testing.B) {
+	store := skip_list.New[int]()
+	keys := parallelBenchmarkKeys()
+	for i, key := range keys {
+		store.Insert(key, i)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := rand.Intn(len(keys))
+		for pb.Next() {
+			if i%10 == 0 {
+				store.Insert(keys[i%len(keys)], i)
+			} else {
+				store.Search(keys[i%len(keys)])
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkMixedLockedMapParallel(b *testing.B) {
+	store := make(mapkeys.Store[int])
+	var mu sync.RWMutex
+	keys := parallelBenchmarkKeys()
+	for i, key := range keys {
+		store.Insert(key, i)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := rand.Intn(len(keys))
+		for pb.Next() {
+			if i%10 == 0 {
+				mu.Lock()
+				store.Insert(keys[i%len(keys)], i)
+				mu.Unlock()
+			} else {
+				mu.RLock()
+				store.Search(keys[i%len(keys)])
+				mu.RUnlock()
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkSearchPrefixSkipListParallel(b *testing.B) {
+	store := skip_list.New[int]()
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+	prefixes := []string{"business_revenue", "profits", "testing", "profits.revenue.top_line"}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if i%10 == 0 {
+				store.Insert("profits.revenue.top_line.parallel", i)
+			} else {
+				store.SearchPrefix(prefixes[i%len(prefixes)])
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkSearchPrefixLockedTrieChunkedParallel(b *testing.B) {
+	store := prefix_trie_chunked.New[int]()
+	var mu sync.RWMutex
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+	prefixes := []string{"business_revenue", "profits", "testing", "profits.revenue.top_line"}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if i%10 == 0 {
+				mu.Lock()
+				store.Insert("profits.revenue.top_line.parallel", i)
+				mu.Unlock()
+			} else {
+				mu.RLock()
+				store.SearchPrefix(prefixes[i%len(prefixes)])
+				mu.RUnlock()
+			}
+			i++
+		}
+	})
+}
+
+// /////////////////
+// // Ternary search tree
+// /////////////////
+func BenchmarkInsertTernarySearchTreeRandom(b *testing.B) {
+	store := ternary_search_tree.New[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		store.Insert(data[i], i)
+	}
+}
+
+func BenchmarkInsertTernarySearchTreeRealistic(b *testing.B) {
+	store := ternary_search_tree.New[int]()
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key, val := range realisticBenchmarkData {
+			store.Insert(key, val)
+		}
+	}
+}
+
+func BenchmarkSearchTernarySearchTreeRandom(b *testing.B) {
+	store := ternary_search_tree.New[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	for val, key := range data {
+		// insert into store
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// The function we're testing
+		store.Search(data[i])
+	}
+}
+
+func BenchmarkSearchTernarySearchTreeRealistic(b *testing.B) {
+	store := ternary_search_tree.New[int]()
+
+	// insert into store
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key := range realisticBenchmarkData {
+			// The function we're testing
+			store.Search(key)
+		}
+	}
+
+}
+
+func BenchmarkSearchPrefixTernarySearchTreeRandom(b *testing.B) {
+	store := ternary_search_tree.New[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	for val, key := range data {
+		// insert into store
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	var testString string
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		// chop the string in half
+		testString = data[i]
+		half := testString[:(len(testString) / 2)]
+		testString = half
+		b.StartTimer()
+
+		// The function we're testing
+		store.SearchPrefix(testString)
+	}
+}
+
+func BenchmarkSearchPrefixTernarySearchTreeRealistic(b *testing.B) {
+	store := ternary_search_tree.New[int]()
+
+	// insert into store
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// short keys
+		store.SearchPrefix("business_revenue")
+
+		// medium keys
+		store.SearchPrefix("profits")
+
+		// long keys
+		store.SearchPrefix("testing")
+
+		// half of a medium key
+		store.SearchPrefix("profits.revenue.top_line")
+
+		// half of a wildly long key
+		store.SearchPrefix("testing.very.long.string.keys.with.many.many.many.many.segments.jhsdkfjhskdjhfks.kjhsdkjfhskdjhfksjhdf.kjshdkhskdjhfksjdhfkjshdfkjh.kjhsdkfjhskdjfhksjhdf.sd.sdf.sdf.sdf.sd.fs.dfs.dfs.dfs.df")
+	}
+}
+
+// /////////////////
+// // Burst trie
+// /////////////////
+func BenchmarkInsertBurstTrieRandom(b *testing.B) {
+	store := burst_trie.New[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		store.Insert(data[i], i)
+	}
+}
+
+func BenchmarkInsertBurstTrieRealistic(b *testing.B) {
+	store := burst_trie.New[int]()
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key, val := range realisticBenchmarkData {
+			store.Insert(key, val)
+		}
+	}
+}
+
+func BenchmarkSearchBurstTrieRandom(b *testing.B) {
+	store := burst_trie.New[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	for val, key := range data {
+		// insert into store
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// The function we're testing
+		store.Search(data[i])
+	}
+}
+
+func BenchmarkSearchBurstTrieRealistic(b *testing.B) {
+	store := burst_trie.New[int]()
+
+	// insert into store
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key := range realisticBenchmarkData {
+			// The function we're testing
+			store.Search(key)
+		}
+	}
+
+}
+
+func BenchmarkSearchPrefixBurstTrieRandom(b *testing.B) {
+	store := burst_trie.New[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	for val, key := range data {
+		// insert into store
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	var testString string
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		// chop the string in half
+		testString = data[i]
+		half := testString[:(len(testString) / 2)]
+		testString = half
+		b.StartTimer()
+
+		// The function we're testing
+		store.SearchPrefix(testString)
+	}
+}
+
+func BenchmarkSearchPrefixBurstTrieRealistic(b *testing.B) {
+	store := burst_trie.New[int]()
+
+	// insert into store
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// short keys
+		store.SearchPrefix("business_revenue")
+
+		// medium keys
+		store.SearchPrefix("profits")
+
+		// long keys
+		store.SearchPrefix("testing")
+
+		// half of a medium key
+		store.SearchPrefix("profits.revenue.top_line")
+
+		// half of a wildly long key
+		store.SearchPrefix("testing.very.long.string.keys.with.many.many.many.many.segments.jhsdkfjhskdjhfks.kjhsdkjfhskdjhfksjhdf.kjshdkhskdjhfksjdhfkjshdfkjh.kjhsdkfjhskdjfhksjhdf.sd.sdf.sdf.sdf.sd.fs.dfs.dfs.dfs.df")
+	}
+}
+
+// /////////////////
+// // Hybrid (map + chunked trie)
+// /////////////////
+func BenchmarkInsertHybridRandom(b *testing.B) {
+	store := hybrid.New[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		store.Insert(data[i], i)
+	}
+}
+
+func BenchmarkInsertHybridRealistic(b *testing.B) {
+	store := hybrid.New[int]()
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key, val := range realisticBenchmarkData {
+			store.Insert(key, val)
+		}
+	}
+}
+
+func BenchmarkSearchHybridRandom(b *testing.B) {
+	store := hybrid.New[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	for val, key := range data {
+		// insert into store
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// The function we're testing
+		store.Search(data[i])
+	}
+}
+
+func BenchmarkSearchHybridRealistic(b *testing.B) {
+	store := hybrid.New[int]()
+
+	// insert into store
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key := range realisticBenchmarkData {
+			// The function we're testing
+			store.Search(key)
+		}
+	}
+
+}
+
+// random keys have no dots, so half a key is never a whole chunk and matches nothing in the hybrid: this only measures
+// misses. BenchmarkSearchPrefixHybridLarge compares it with the map on prefixes it can answer.
+func BenchmarkSearchPrefixHybridRandom(b *testing.B) {
+	store := hybrid.New[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	for val, key := range data {
+		// insert into store
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	var testString string
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		// chop the string in half
+		testString = data[i]
+		half := testString[:(len(testString) / 2)]
+		testString = half
+		b.StartTimer()
+
+		// The function we're testing
+		store.SearchPrefix(testString)
+	}
+}
+
+func BenchmarkSearchPrefixHybridRealistic(b *testing.B) {
+	store := hybrid.New[int]()
+
+	// insert into store
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// short keys
+		store.SearchPrefix("business_revenue")
+
+		// medium keys
+		store.SearchPrefix("profits")
+
+		// long keys
+		store.SearchPrefix("testing")
+
+		// half of a medium key
+		store.SearchPrefix("profits.revenue.top_line")
+
+		// half of a wildly long key
+		store.SearchPrefix("testing.very.long.string.keys.with.many.many.many.many.segments.jhsdkfjhskdjhfks.kjhsdkjfhskdjhfksjhdf.kjshdkhskdjhfksjdhfkjshdfkjh.kjhsdkfjhskdjfhksjhdf.sd.sdf.sdf.sdf.sd.fs.dfs.dfs.dfs.df")
+	}
+}
+
+// /////////////////
+// // Large keyset: the map and the hybrid on a big metric namespace, with whole-chunk prefixes
+// /////////////////
+
+var largeKeys = flag.Int("large.keys", 100000, "number of keys in the large keyset benchmarks")
+
+// largeKeyset is a metric namespace and a stream of operations of each kind on it (Zipf-distributed, like the mixed
+// workload), shared by all the Large benchmarks. Prefixes are always whole segments, which is what the hybrid's
+// trie can answer.
+type largeKeyset struct {
+	keys                    []string
+	reads, writes, prefixes []string
+}
+
+var loadLargeKeyset = sync.OnceValue(func() largeKeyset {
+	g := workload.New(workload.MetricNamespace(*largeKeys))
+	ks := largeKeyset{keys: g.Keys()}
+	for _, op := range g.Ops(1 << 20) {
+		switch op.Kind {
+		case workload.Read:
+			ks.reads = append(ks.reads, op.Key)
+		case workload.Write:
+			ks.writes = append(ks.writes, op.Key)
+		case workload.Prefix:
+			ks.prefixes = append(ks.prefixes, op.Key)
+		}
+	}
+	return ks
+})
+
+// the stores are loaded once and shared too; the Insert benchmarks only overwrite existing keys, so they stay the same size
+var (
+	loadLargeMap = sync.OnceValue(func() mapkeys.Store[int] {
+		store := make(mapkeys.Store[int])
+		for i, key := range loadLargeKeyset().keys {
+			store.Insert(key, i)
+		}
+		return store
+	})
+	loadLargeHybrid = sync.OnceValue(func() *hybrid.Store[int] {
+		store := hybrid.New[int]()
+		for i, key := range loadLargeKeyset().keys {
+			store.Insert(key, i)
+		}
+		return store
+	})
+)
+
+// benchmarkLarge calls op b.N times, cycling through keys
+func benchmarkLarge(b *testing.B, keys []string, op func(key string, i int)) {
+	// Setup complete, let's bench
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		op(keys[i%len(keys)], i)
+	}
+}
+
+func BenchmarkInsertMapLarge(b *testing.B) {
+	store := loadLargeMap()
+	benchmarkLarge(b, loadLargeKeyset().writes, store.Insert)
+}
+
+func BenchmarkInsertHybridLarge(b *testing.B) {
+	store := loadLargeHybrid()
+	benchmarkLarge(b, loadLargeKeyset().writes, store.Insert)
+}
+
+func BenchmarkSearchMapLarge(b *testing.B) {
+	store := loadLargeMap()
+	benchmarkLarge(b, loadLargeKeyset().reads, func(key string, _ int) { store.Search(key) })
+}
+
+func BenchmarkSearchHybridLarge(b *testing.B) {
+	store := loadLargeHybrid()
+	benchmarkLarge(b, loadLargeKeyset().reads, func(key string, _ int) { store.Search(key) })
+}
+
+// the map scans every key for every prefix, the hybrid only visits the keys under it
+func BenchmarkSearchPrefixMapLarge(b *testing.B) {
+	store := loadLargeMap()
+	benchmarkLarge(b, loadLargeKeyset().prefixes, func(prefix string, _ int) { store.SearchPrefix(prefix) })
+}
+
+func BenchmarkSearchPrefixHybridLarge(b *testing.B) {
+	store := loadLargeHybrid()
+	benchmarkLarge(b, loadLargeKeyset().prefixes, func(prefix string, _ int) { store.SearchPrefix(prefix) })
+}
+
+// /////////////////
+// // Double-array trie
+// /////////////////
+func doubleArrayData(data map[string]int) []double_array_trie.KeyValue[int] {
+	kvs := make([]double_array_trie.KeyValue[int], 0, len(data))
+	for key, val := range data {
+		kvs = append(kvs, double_array_trie.KeyValue[int]{Key: key, Value: val})
+	}
+	return kvs
+}
+
+func doubleArrayRandomData(data map[int]string) []double_array_trie.KeyValue[int] {
+	kvs := make([]double_array_trie.KeyValue[int], 0, len(data))
+	for val, key := range data {
+		kvs = append(kvs, double_array_trie.KeyValue[int]{Key: key, Value: val})
+	}
+	return kvs
+}
+
+func BenchmarkBuildDoubleArrayRealistic(b *testing.B) {
+	data := doubleArrayData(realisticBenchmarkData)
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		double_array_trie.Build(data)
+	}
+}
+
+func BenchmarkSearchDoubleArrayRandom(b *testing.B) {
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+	store := double_array_trie.Build(doubleArrayRandomData(data))
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// The function we're testing
+		store.Search(data[i])
+	}
+}
+
+func BenchmarkSearchDoubleArrayRealistic(b *testing.B) {
+	store := double_array_trie.Build(doubleArrayData(realisticBenchmarkData))
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key := range realisticBenchmarkData {
+			// The function we're testing
+			store.Search(key)
+		}
+	}
+}
+
+func BenchmarkSearchPrefixDoubleArrayRandom(b *testing.B) {
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+	store := double_array_trie.Build(doubleArrayRandomData(data))
+
+	// Setup complete, let's bench
+	var testString string
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		// chop the string in half
+		testString = data[i]
+		half := testString[:(len(testString) / 2)]
+		testString = half
+		b.StartTimer()
+
+		// The function we're testing
+		store.SearchPrefix(testString)
+	}
+}
+
+func BenchmarkSearchPrefixDoubleArrayRealistic(b *testing.B) {
+	store := double_array_trie.Build(doubleArrayData(realisticBenchmarkData))
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// short keys
+		store.SearchPrefix("business_revenue")
+
+		// medium keys
+		store.SearchPrefix("profits")
+
+		// long keys
+		store.SearchPrefix("testing")
+
+		// half of a medium key
+		store.SearchPrefix("profits.revenue.top_line")
+
+		// half of a wildly long key
+		store.SearchPrefix("testing.very.long.string.keys.with.many.many.many.many.segments.jhsdkfjhskdjhfks.kjhsdkjfhskdjhfksjhdf.kjshdkhskdjhfksjdhfkjshdfkjh.kjhsdkfjhskdjfhksjhdf.sd.sdf.sdf.sdf.sd.fs.dfs.dfs.dfs.df")
+	}
+}
+
+// /////////////////
+// // LOUDS trie
+// /////////////////
+func loudsRealisticStore() *louds_trie.Trie[int64] {
+	kvs := make([]louds_trie.KeyValue[int64], 0, len(realisticBenchmarkData))
+	for key, val := range realisticBenchmarkData {
+		kvs = append(kvs, louds_trie.KeyValue[int64]{Key: key, Value: int64(val)})
+	}
+	return louds_trie.Build(kvs)
+}
+
+func BenchmarkSearchLoudsRealistic(b *testing.B) {
+	store := loudsRealisticStore()
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key := range realisticBenchmarkData {
+			// The function we're testing
+			store.Search(key)
+		}
+	}
+}
+
+func BenchmarkSearchPrefixLoudsRealistic(b *testing.B) {
+	store := loudsRealisticStore()
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// short keys
+		store.SearchPrefix("business_revenue")
+
+		// medium keys
+		store.SearchPrefix("profits")
+
+		// long keys
+		store.SearchPrefix("testing")
+
+		// half of a medium key
+		store.SearchPrefix("profits.revenue.top_line")
+
+		// half of a wildly long key
+		store.SearchPrefix("testing.very.long.string.keys.with.many.many.many.many.segments.jhsdkfjhskdjhfks.kjhsdkjfhskdjhfksjhdf.kjshdkhskdjhfksjdhfkjshdfkjh.kjhsdkfjhskdjfhksjhdf.sd.sdf.sdf.sdf.sd.fs.dfs.dfs.dfs.df")
+	}
+}
+
+// /////////////////
+// // FST
+// /////////////////
+func fstRealisticStore() *fst.FST {
+	kvs := make([]fst.KeyValue, 0, len(realisticBenchmarkData))
+	for key, val := range realisticBenchmarkData {
+		kvs = append(kvs, fst.KeyValue{Key: key, Value: uint64(val)})
+	}
+	return fst.Build(kvs)
+}
+
+func BenchmarkBuildFSTRealistic(b *testing.B) {
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		fstRealisticStore()
+	}
+}
+
+func BenchmarkSearchFSTRealistic(b *testing.B) {
+	store := fstRealisticStore()
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key := range realisticBenchmarkData {
+			// The function we're testing
+			store.Search(key)
+		}
+	}
+}
+
+func BenchmarkSearchPrefixFSTRealistic(b *testing.B) {
+	store := fstRealisticStore()
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// short keys
+		store.SearchPrefix("business_revenue")
+
+		// medium keys
+		store.SearchPrefix("profits")
+
+		// long keys
+		store.SearchPrefix("testing")
+
+		// half of a medium key
+		store.SearchPrefix("profits.revenue.top_line")
+
+		// half of a wildly long key
+		store.SearchPrefix("testing.very.long.string.keys.with.many.many.many.many.segments.jhsdkfjhskdjhfks.kjhsdkjfhskdjhfksjhdf.kjshdkhskdjhfksjdhfkjshdfkjh.kjhsdkfjhskdjfhksjhdf.sd.sdf.sdf.sdf.sd.fs.dfs.dfs.dfs.df")
+	}
+}
+
+func BenchmarkLevenshteinFSTRealistic(b *testing.B) {
+	store := fstRealisticStore()
+	aut := fst.Levenshtein{Query: "profits.revenue.top_lnie", Distance: 2}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		fst.Intersect[[]int](store, aut, func(key string, output uint64) bool { return true })
+	}
+}
+
+// /////////////////
+// // Trie Chunked
+// /////////////////
+func BenchmarkInsertTrieChunkedRandom(b *testing.B) {
+	store := prefix_trie_chunked.New[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		store.Insert(data[i], i)
+	}
+}
+
+func BenchmarkInsertTrieChunkedRealistic(b *testing.B) {
+	store := prefix_trie_chunked.New[int]()
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key, val := range realisticBenchmarkData {
+			store.Insert(key, val)
+		}
+	}
+}
+
+func BenchmarkSearchTrieChunkedRandom(b *testing.B) {
+	store := prefix_trie_chunked.New[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	for val, key := range data {
+		// insert into store
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// The function we're testing
+		store.Search(data[i])
+	}
+}
+
+func BenchmarkSearchTrieChunkedRealistic(b *testing.B) {
+	store := prefix_trie_chunked.New[int]()
+
+	// insert into store
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key, _ := range realisticBenchmarkData {
+			// The function we're testing
+			store.Search(key)
+		}
+	}
+
+}
+
+func BenchmarkSearchPrefixTrieChunkedRandom(b *testing.B) {
+	store := prefix_trie_chunked.New[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	for val, key := range data {
+		// insert into store
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	var testString string
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		// chop the string in half
+		testString = data[i]
+		half := testString[:(len(testString) / 2)]
+		testString = half
+		b.StartTimer()
+
+		// The function we're testing
+		store.SearchPrefix(testString)
+	}
+}
+
+func BenchmarkSearchPrefixTrieChunkedRealistic(b *testing.B) {
+	store := prefix_trie_chunked.New[int]()
+
+	// insert into store
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// short keys
+		store.SearchPrefix("business_revenue")
+
+		// medium keys
+		store.SearchPrefix("profits")
+
+		// long keys
+		store.SearchPrefix("testing")
+
+		// half of a medium key
+		store.SearchPrefix("profits.revenue.top_line")
+
+		// half of a wildly long key
+		store.SearchPrefix("testing.very.long.string.keys.with.many.many.many.many.segments.jhsdkfjhskdjhfks.kjhsdkjfhskdjhfksjhdf.kjshdkhskdjhfksjdhfkjshdfkjh.kjhsdkfjhskdjfhksjhdf.sd.sdf.sdf.sdf.sd.fs.dfs.dfs.dfs.df")
+	}
+}
+
+func BenchmarkWalkTrieChunkedRealistic(b *testing.B) {
+	store := prefix_trie_chunked.New[int]()
+
+	// insert into store
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+
+	// count the keys we see, the same work SearchPrefix does minus building the result map
+	var keys int
+	visitor := func(key []byte, val int, hasValue bool) prefix_trie_chunked.WalkAction {
+		if hasValue {
+			keys++
+		}
+		return prefix_trie_chunked.Continue
+	}
+
+	// Setup complete, let's bench
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// short keys
+		store.Walk("business_revenue", visitor)
+
+		// medium keys
+		store.Walk("profits", visitor)
+
+		// long keys
+		store.Walk("testing", visitor)
+
+		// half of a medium key
+		store.Walk("profits.revenue.top_line", visitor)
+
+		// half of a wildly long key
+		store.Walk("testing.very.long.string.keys.with.many.many.many.many.segments.jhsdkfjhskdjhfks.kjhsdkjfhskdjhfksjhdf.kjshdkhskdjhfksjdhfkjshdfkjh.kjhsdkfjhskdjfhksjhdf.sd.sdf.sdf.sdf.sd.fs.dfs.dfs.dfs.df", visitor)
+	}
+}
+
+// sortedTrieChunkedData returns the data in the order prefix_trie_chunked.NewFromSortedSlice wants
+func sortedTrieChunkedData(data map[string]int) []prefix_trie_chunked.KeyValue[int] {
+	kvs := make([]prefix_trie_chunked.KeyValue[int], 0, len(data))
+	for key, val := range data {
+		kvs = append(kvs, prefix_trie_chunked.KeyValue[int]{Key: key, Value: val})
+	}
+	sort.Slice(kvs, func(i, j int) bool { return prefix_trie_chunked.CompareKeys(kvs[i].Key, kvs[j].Key) < 0 })
+	return kvs
+}
+
+// building a whole trie per op, one Insert at a time (compare with BenchmarkBuildSortedTrieChunked*)
+func BenchmarkBuildInsertTrieChunkedRealistic(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		store := prefix_trie_chunked.New[int]()
+		for key, val := range realisticBenchmarkData {
+			store.Insert(key, val)
+		}
+	}
+}
+
+func BenchmarkBuildSortedTrieChunkedRealistic(b *testing.B) {
+	data := sortedTrieChunkedData(realisticBenchmarkData)
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		prefix_trie_chunked.NewFromSortedSlice(data)
+	}
+}
+
+func BenchmarkBuildInsertTrieChunkedRandom(b *testing.B) {
+	data := make(map[string]int)
+	for val, key := range makeRandomDataMap(1000) {
+		data[key] = val
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		store := prefix_trie_chunked.New[int]()
+		for key, val := range data {
+			store.Insert(key, val)
+		}
+	}
+}
+
+func BenchmarkBuildSortedTrieChunkedRandom(b *testing.B) {
+	data := make(map[string]int)
+	for val, key := range makeRandomDataMap(1000) {
+		data[key] = val
+	}
+	sorted := sortedTrieChunkedData(data)
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		prefix_trie_chunked.NewFromSortedSlice(sorted)
+	}
+}
+
+func BenchmarkSearchFrozenTrieChunkedRealistic(b *testing.B) {
+	store := prefix_trie_chunked.New[int]()
+
+	// insert into store
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+	frozen := store.Freeze()
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key := range realisticBenchmarkData {
+			// The function we're testing
+			frozen.Search(key)
+		}
+	}
+}
+
+func BenchmarkSearchPrefixFrozenTrieChunkedRealistic(b *testing.B) {
+	store := prefix_trie_chunked.New[int]()
+
+	// insert into store
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+	frozen := store.Freeze()
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// short keys
+		frozen.SearchPrefix("business_revenue")
+
+		// medium keys
+		frozen.SearchPrefix("profits")
+
+		// long keys
+		frozen.SearchPrefix("testing")
+
+		// half of a medium key
+		frozen.SearchPrefix("profits.revenue.top_line")
+
+		// half of a wildly long key
+		frozen.SearchPrefix("testing.very.long.string.keys.with.many.many.many.many.segments.jhsdkfjhskdjhfks.kjhsdkjfhskdjhfksjhdf.kjshdkhskdjhfksjdhfkjshdfkjh.kjhsdkfjhskdjfhksjhdf.sd.sdf.sdf.sdf.sd.fs.dfs.dfs.dfs.df")
+	}
+}
+
+// retainedBytes reports how much live heap build's result holds on to, per key, next to the time it takes to build
+func retainedBytes(b *testing.B, numKeys int, build func() any) {
+	var before, after runtime.MemStats
+	var retained uint64
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		b.StartTimer()
+
+		store := build()
+
+		b.StopTimer()
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		runtime.KeepAlive(store)
+		retained = after.HeapAlloc - before.HeapAlloc
+		b.StartTimer()
+	}
+	b.ReportMetric(float64(retained)/float64(numKeys), "heap-B/key")
+}
+
+// Most chunked trie nodes only have one or two children, so this is where the adaptive child sets pay off
+func BenchmarkMemoryTrieChunkedRealistic(b *testing.B) {
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	retainedBytes(b, len(realisticBenchmarkData), func() any {
+		store := prefix_trie_chunked.New[int]()
+		for key, val := range realisticBenchmarkData {
+			store.Insert(key, val)
+		}
+		return store
+	})
+}
+
+func BenchmarkMemoryTrieChunkedRandom(b *testing.B) {
+	data := makeRandomDataMap(10000)
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	retainedBytes(b, len(data), func() any {
+		store := prefix_trie_chunked.New[int]()
+		for val, key := range data {
+			store.Insert(key, val)
+		}
+		return store
+	})
+}
+
+// deepBenchmarkKey makes keys shaped like the testing.very.long... ones in realisticBenchmarkData: long, with the
+// same few chunks repeated at every level and under every host
+func deepBenchmarkKey(i int) string {
+	metrics := []string{"net", "basket", "top_line", "bottom_line", "let's.double_click_on_that"}
+	return fmt.Sprintf("testing.very.long.string.keys.host_%d.segments.sd.sdf.sdf.sdf.sd.fs.dfs.dfs.dfs.df.sdf.sdf.sdf.region_%d.revenue.%s", i/100, i%20, metrics[(i/20)%len(metrics)])
+}
+
+const deepBenchmarkKeys = 10000
+
+// The keys are made inside build, so a trie that keeps slices of them pays for keeping them alive
+func BenchmarkMemoryTrieChunkedDeep(b *testing.B) {
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	retainedBytes(b, deepBenchmarkKeys, func() any {
+		store := prefix_trie_chunked.New[int]()
+		for i := 0; i < deepBenchmarkKeys; i++ {
+			store.Insert(deepBenchmarkKey(i), i)
+		}
+		return store
+	})
+}
+
+func BenchmarkMemoryInternedTrieChunkedDeep(b *testing.B) {
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	retainedBytes(b, deepBenchmarkKeys, func() any {
+		store := prefix_trie_chunked.NewInterned[int]()
+		for i := 0; i < deepBenchmarkKeys; i++ {
+			store.Insert(deepBenchmarkKey(i), i)
+		}
+		return store
+	})
+}
+
+// realisticBenchmarkData's keys are constants that don't live on the heap, so here interning only adds its table
+func BenchmarkMemoryInternedTrieChunkedRealistic(b *testing.B) {
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	retainedBytes(b, len(realisticBenchmarkData), func() any {
+		store := prefix_trie_chunked.NewInterned[int]()
+		for key, val := range realisticBenchmarkData {
+			store.Insert(key, val)
+		}
+		return store
+	})
+}
+
+func BenchmarkSearchInternedTrieChunkedRealistic(b *testing.B) {
+	store := prefix_trie_chunked.NewInterned[int]()
+
+	// insert into store
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key := range realisticBenchmarkData {
+			// The function we're testing
+			store.Search(key)
+		}
+	}
+}
+
+// /////////////////
+// // GC cost: pointer trees vs. arenas
+// /////////////////
+
+// GC pauses only show up once there's a lot to trace, which takes millions of keys and a few GB of RAM for the
+// pointer-based tries, so these are skipped unless -gckeys says how many to load
+var gcKeys = flag.Int("gckeys", 0, "number of keys loaded for the GC benchmarks (they're skipped if it's 0)")
+
+// gcBenchmarkKey makes keys with a realistic amount of shared prefixes, like service.host.metric
+func gcBenchmarkKey(i int) string {
+	return fmt.Sprintf("service_%d.host_%d.metric_%d", i%100, (i/100)%1000, i/100000)
+}
+
+// gcLoaded is the store the last GC benchmark loaded. Every benchmark runs a few times while b.N is worked out,
+// and this keeps it from loading millions of keys each time. Only one store is kept, so two of them never have
+// to fit in memory at once.
+var gcLoaded struct {
+	name  string
+	store any
+}
+
+// benchmarkGC times full garbage collections with the store from load loaded, and reports the stop-the-world
+// pause per collection and the live heap next to the time per collection
+func benchmarkGC(b *testing.B, load func() any) {
+	if *gcKeys == 0 {
+		b.Skip("run with -gckeys=N")
+	}
+	if gcLoaded.name != b.Name() {
+		gcLoaded.name, gcLoaded.store = "", nil
+		runtime.GC()
+		gcLoaded.name, gcLoaded.store = b.Name(), load()
+	}
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		runtime.GC()
+	}
+
+	b.StopTimer()
+	runtime.ReadMemStats(&after)
+	b.ReportMetric(float64(after.PauseTotalNs-before.PauseTotalNs)/float64(after.NumGC-before.NumGC), "pause-ns/gc")
+	b.ReportMetric(float64(after.HeapAlloc)/(1<<20), "heap-MB")
+}
+
+func BenchmarkGCTrie(b *testing.B) {
+	benchmarkGC(b, func() any {
+		store := prefix_trie.New[int]()
+		for i := 0; i < *gcKeys; i++ {
+			store.Insert(gcBenchmarkKey(i), i)
+		}
+		return store
+	})
+}
+
+func BenchmarkGCArenaTrie(b *testing.B) {
+	benchmarkGC(b, func() any {
+		store := prefix_trie.NewArena[int]()
+		for i := 0; i < *gcKeys; i++ {
+			store.Insert(gcBenchmarkKey(i), i)
+		}
+		return store
+	})
+}
+
+func BenchmarkGCTrieChunked(b *testing.B) {
+	benchmarkGC(b, func() any {
+		store := prefix_trie_chunked.New[int]()
+		for i := 0; i < *gcKeys; i++ {
+			store.Insert(gcBenchmarkKey(i), i)
+		}
+		return store
+	})
+}
+
+func BenchmarkGCArenaTrieChunked(b *testing.B) {
+	benchmarkGC(b, func() any {
+		store := prefix_trie_chunked.NewArena[int]()
+		for i := 0; i < *gcKeys; i++ {
+			store.Insert(gcBenchmarkKey(i), i)
+		}
+		return store
+	})
+}
+
+func BenchmarkInsertArenaTrieRandom(b *testing.B) {
+	store := prefix_trie.NewArena[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		store.Insert(data[i], i)
+	}
+}
+
+func BenchmarkInsertArenaTrieChunkedRandom(b *testing.B) {
+	store := prefix_trie_chunked.NewArena[int]()
+
+	// make a slice of test case strings, just long enough for all benchmark runs to complete
+	data := makeRandomDataMap(b.N)
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		store.Insert(data[i], i)
+	}
+}
+
+func BenchmarkSearchArenaTrieChunkedRealistic(b *testing.B) {
+	store := prefix_trie_chunked.NewArena[int]()
+
+	// insert into store
+	for key, val := range realisticBenchmarkData {
+		store.Insert(key, val)
+	}
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for key := range realisticBenchmarkData {
+			// The function we're testing
+			store.Search(key)
+		}
+	}
+}
+
+// /////////////////
+// // Mixed workload: reads, writes and prefix queries on a metric namespace, hot keys first
+// /////////////////
+
+var workloadKeys = flag.Int("workload.keys", 100000, "number of keys in the mixed workload benchmarks")
+
+// benchmarkWorkload loads a store with a metric namespace and then runs b.N operations on it,
+// 80% reads, 15% writes and 5% prefix queries, on Zipf-distributed keys
+func benchmarkWorkload(b *testing.B, insert func(key string, val int), search func(key string), searchPrefix func(prefix string)) {
+	g := workload.New(workload.MetricNamespace(*workloadKeys))
+	for i, key := range g.Keys() {
+		insert(key, i)
+	}
+	ops := g.Ops(b.N)
+
+	// Setup complete, let's bench
+	b.ResetTimer()
+
+	for i, op := range ops {
+		switch op.Kind {
+		case workload.Read:
+			search(op.Key)
+		case workload.Write:
+			insert(op.Key, i)
+		case workload.Prefix:
+			searchPrefix(op.Key)
+		}
+	}
+}
+
+func BenchmarkWorkloadMap(b *testing.B) {
+	store := make(mapkeys.Store[int])
+	benchmarkWorkload(b, store.Insert, func(key string) { store.Search(key) }, func(prefix string) { store.SearchPrefix(prefix) })
+}
+
+func BenchmarkWorkloadSortedArray(b *testing.B) {
+	store := sorted_array.New[int]()
+	benchmarkWorkload(b, store.Insert, func(key string) { store.Search(key) }, func(prefix string) { store.SearchPrefix(prefix) })
+}
+
+func BenchmarkWorkloadBPlusTree(b *testing.B) {
+	store := bplus_tree.New[int]()
+	benchmarkWorkload(b, store.Insert, func(key string) { store.Search(key) }, func(prefix string) { store.SearchPrefix(prefix) })
+}
+
+func BenchmarkWorkloadSkipList(b *testing.B) {
+	store := skip_list.New[int]()
+	benchmarkWorkload(b, store.Insert, func(key string) { store.Search(key) }, func(prefix string) { store.SearchPrefix(prefix) })
+}
+
+func BenchmarkWorkloadBurstTrie(b *testing.B) {
+	store := burst_trie.New[int]()
+	benchmarkWorkload(b, store.Insert, func(key string) { store.Search(key) }, func(prefix string) { store.SearchPrefix(prefix) })
+}
+
+func BenchmarkWorkloadTrieChunked(b *testing.B) {
+	store := prefix_trie_chunked.New[int]()
+	benchmarkWorkload(b, store.Insert, func(key string) { store.Search(key) }, func(prefix string) { store.SearchPrefix(prefix) })
+}
+
+func BenchmarkWorkloadInternedTrieChunked(b *testing.B) {
+	store := prefix_trie_chunked.NewInterned[int]()
+	benchmarkWorkload(b, store.Insert, func(key string) { store.Search(key) }, func(prefix string) { store.SearchPrefix(prefix) })
+}
+
+func BenchmarkWorkloadHybrid(b *testing.B) {
+	store := hybrid.New[int]()
+	benchmarkWorkload(b, store.Insert, func(key string) { store.Search(key) }, func(prefix string) { store.SearchPrefix(prefix) })
+}
+
+// /////////////////
+// // Store comparison: every store loaded with the same keys
+// /////////////////
+
+// go test -run Compare -compare prints two tables: the time per pass of every store's realistic benchmarks,
+// and how much memory each store holds on to after loading the same keys and how long a full GC takes with it loaded,
+// plus conclusions drawn from the first one. -compare.readme also writes them into README.md as its benchmark section.
+var (
+	compare       = flag.Bool("compare", false, "run the store comparison")
+	compareKeys   = flag.Int("compare.keys", 50000, "number of keys per dataset in the memory footprint table")
+	compareReadme = flag.Bool("compare.readme", false, "replace the benchmark section of README.md with the comparison")
+)
+
+// the README's benchmark section starts with this heading and runs until the next heading of the same level
+const benchmarkHeading = "## Benchmarks"
+
+type footprintDataset struct {
+	name string
+	keys []string
+}
+
+// footprintDatasets makes n distinct keys of each kind
+func footprintDatasets(n int) []footprintDataset {
+	unique := func(next func(i int) string) []string {
+		seen := make(map[string]bool, n)
+		keys := make([]string, 0, n)
+		for i := 0; len(keys) < n; i++ {
+			if key := next(i); !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+		return keys
+	}
+	realistic := make([]string, 0, len(realisticBenchmarkData))
+	for key := range realisticBenchmarkData {
+		realistic = append(realistic, key)
+	}
+	sort.Strings(realistic)
+
+	return []footprintDataset{
+		// shorter than makeRandomDataMap's keys, or the one-rune-per-node trie wouldn't fit in memory
+		{"random", unique(func(int) string { return RandStringBytes(randInt(8, 64)) })},
+		// the realistic keys, repeated under a different host each time
+		{"realistic", unique(func(i int) string {
+			return fmt.Sprintf("host_%d.%s", i/len(realistic), realistic[i%len(realistic)])
+		})},
+		{"hierarchical", workload.New(workload.MetricNamespace(n)).Keys()},
+	}
+}
+
+type comparedStore struct {
+	name string
+	// load builds the store from keys and returns it. Keys are copied first, the way they would be when read from
+	// the network, so a store that keeps slices of its keys pays for them.
+	load func(keys []string) any
+	// the store's realistic benchmarks, nil if it doesn't have one (or doesn't support the operation)
+	insert, search, searchPrefix func(b *testing.B)
+}
+
+var comparedStores = []comparedStore{
+	{"Map", func(keys []string) any {
+		store := make(mapkeys.Store[int])
+		for i, key := range keys {
+			store.Insert(strings.Clone(key), i)
+		}
+		return store
+	}, BenchmarkInsertMapRealistic, BenchmarkSearchMapRealistic, BenchmarkSearchPrefixMapRealistic},
+	{"HAMT", func(keys []string) any {
+		store := hamt.New[int]()
+		for i, key := range keys {
+			store = store.Insert(strings.Clone(key), i)
+		}
+		return store
+	}, BenchmarkInsertHAMTRealistic, BenchmarkSearchHAMTRealistic, nil},
+	{"Sorted Array", func(keys []string) any {
+		store := sorted_array.New[int]()
+		for i, key := range keys {
+			store.Insert(strings.Clone(key), i)
+		}
+		return store
+	}, BenchmarkInsertSortedArrayRealistic, BenchmarkSearchSortedArrayRealistic, BenchmarkSearchPrefixSortedArrayRealistic},
+	{"B+tree", func(keys []string) any {
+		store := bplus_tree.New[int]()
+		for i, key := range keys {
+			store.Insert(strings.Clone(key), i)
+		}
+		return store
+	}, BenchmarkInsertBPlusTreeRealistic, BenchmarkSearchBPlusTreeRealistic, BenchmarkSearchPrefixBPlusTreeRealistic},
+	{"Skip List", func(keys []string) any {
+		store := skip_list.New[int]()
+		for i, key := range keys {
+			store.Insert(strings.Clone(key), i)
+		}
+		return store
+	}, BenchmarkInsertSkipListRealistic, BenchmarkSearchSkipListRealistic, BenchmarkSearchPrefixSkipListRealistic},
+	{"Prefix Trie", func(keys []string) any {
+		store := prefix_trie.New[int]()
+		for i, key := range keys {
+			store.Insert(strings.Clone(key), i)
+		}
+		return store
+	}, BenchmarkInsertTrieRealistic, BenchmarkSearchTrieRealistic, BenchmarkSearchPrefixTrieRealistic},
+	{"Prefix Trie (arena)", func(keys []string) any {
+		store := prefix_trie.NewArena[int]()
+		for i, key := range keys {
+			store.Insert(strings.Clone(key), i)
+		}
+		return store
+	}, nil, nil, nil},
+	{"Chunked Prefix Trie", func(keys []string) any {
+		store := prefix_trie_chunked.New[int]()
+		for i, key := range keys {
+			store.Insert(strings.Clone(key), i)
+		}
+		return store
+	}, BenchmarkInsertTrieChunkedRealistic, BenchmarkSearchTrieChunkedRealistic, BenchmarkSearchPrefixTrieChunkedRealistic},
+	{"Chunked Prefix Trie (interned)", func(keys []string) any {
+		store := prefix_trie_chunked.NewInterned[int]()
+		for i, key := range keys {
+			store.Insert(strings.Clone(key), i)
+		}
+		return store
+	}, nil, BenchmarkSearchInternedTrieChunkedRealistic, nil},
+	{"Chunked Prefix Trie (arena)", func(keys []string) any {
+		store := prefix_trie_chunked.NewArena[int]()
+		for i, key := range keys {
+			store.Insert(strings.Clone(key), i)
+		}
+		return store
+	}, nil, BenchmarkSearchArenaTrieChunkedRealistic, nil},
+	{"Chunked Prefix Trie (frozen)", func(keys []string) any {
+		store := prefix_trie_chunked.New[int]()
+		for i, key := range keys {
+			store.Insert(strings.Clone(key), i)
+		}
+		return store.Freeze()
+	}, nil, BenchmarkSearchFrozenTrieChunkedRealistic, BenchmarkSearchPrefixFrozenTrieChunkedRealistic},
+	{"Hybrid", func(keys []string) any {
+		store := hybrid.New[int]()
+		for i, key := range keys {
+			store.Insert(strings.Clone(key), i)
+		}
+		return store
+	}, BenchmarkInsertHybridRealistic, BenchmarkSearchHybridRealistic, BenchmarkSearchPrefixHybridRealistic},
+	{"Double-array Trie", func(keys []string) any {
+		kvs := make([]double_array_trie.KeyValue[int], len(keys))
+		for i, key := range keys {
+			kvs[i] = double_array_trie.KeyValue[int]{Key: strings.Clone(key), Value: i}
+		}
+		return double_array_trie.Build(kvs)
+	}, nil, BenchmarkSearchDoubleArrayRealistic, BenchmarkSearchPrefixDoubleArrayRealistic},
+	{"LOUDS Trie", func(keys []string) any {
+		kvs := make([]louds_trie.KeyValue[int64], len(keys))
+		for i, key := range keys {
+			kvs[i] = louds_trie.KeyValue[int64]{Key: strings.Clone(key), Value: int64(i)}
+		}
+		return louds_trie.Build(kvs)
+	}, nil, BenchmarkSearchLoudsRealistic, BenchmarkSearchPrefixLoudsRealistic},
+	{"FST", func(keys []string) any {
+		kvs := make([]fst.KeyValue, len(keys))
+		for i, key := range keys {
+			kvs[i] = fst.KeyValue{Key: strings.Clone(key), Value: uint64(i)}
+		}
+		return fst.Build(kvs)
+	}, nil, BenchmarkSearchFSTRealistic, BenchmarkSearchPrefixFSTRealistic},
+	{"Ternary Search Tree", func(keys []string) any {
+		store := ternary_search_tree.New[int]()
+		for i, key := range keys {
+			store.Insert(strings.Clone(key), i)
+		}
+		return store
+	}, BenchmarkInsertTernarySearchTreeRealistic, BenchmarkSearchTernarySearchTreeRealistic, BenchmarkSearchPrefixTernarySearchTreeRealistic},
+	{"Burst Trie", func(keys []string) any {
+		store := burst_trie.New[int]()
+		for i, key := range keys {
+			store.Insert(strings.Clone(key), i)
+		}
+		return store
+	}, BenchmarkInsertBurstTrieRealistic, BenchmarkSearchBurstTrieRealistic, BenchmarkSearchPrefixBurstTrieRealistic},
+}
+
+type footprintResult struct {
+	store, dataset string
+	keys           int
+	retained       uint64
+	gcTime         time.Duration
+}
+
+// measureFootprint loads keys into a store and returns how much heap it holds on to once everything else has
+// been collected, and how long a full GC takes while it's alive
+func measureFootprint(store comparedStore, keys []string) (uint64, time.Duration) {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	loaded := store.load(keys)
+
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	var retained uint64
+	if after.HeapAlloc > before.HeapAlloc {
+		retained = after.HeapAlloc - before.HeapAlloc
+	}
+
+	const gcRuns = 5
+	start := time.Now()
+	for i := 0; i < gcRuns; i++ {
+		runtime.GC()
+	}
+	gcTime := time.Since(start) / gcRuns
+	runtime.KeepAlive(loaded)
+	return retained, gcTime
+}
+
+// footprintTable formats results as a markdown table, one row per store and one column group per dataset
+func footprintTable(datasets []footprintDataset, results []footprintResult) string {
+	var table strings.Builder
+	fmt.Fprintf(&table, "%d keys per dataset. Retained heap in MB / bytes per key / time for a full GC with the store loaded "+
+		"(the datasets are loaded too, which puts a floor under the GC times).\n\n", len(datasets[0].keys))
+	table.WriteString("| Store |")
+	for _, dataset := range datasets {
+		fmt.Fprintf(&table, " %s |", dataset.name)
+	}
+	table.WriteString("\n|---|")
+	for range datasets {
+		table.WriteString("---|")
+	}
+	for i, result := range results {
+		if i%len(datasets) == 0 {
+			fmt.Fprintf(&table, "\n| %s |", result.store)
+		}
+		fmt.Fprintf(&table, " %.1f MB / %d B / %.1f ms |", float64(result.retained)/(1<<20), result.retained/uint64(result.keys),
+			float64(result.gcTime)/float64(time.Millisecond))
+	}
+	table.WriteString("\n")
+	return table.String()
+}
+
+// benchmarkOps are the operations in the timing table, in the order of comparedStore's benchmarks
+var benchmarkOps = []string{"Insert", "Search", "SearchPrefix"}
+
+// runTimings runs every store's realistic benchmarks. A store's result for an operation it has no benchmark for
+// is zero (N == 0).
+func runTimings(stores []comparedStore) [][]testing.BenchmarkResult {
+	timings := make([][]testing.BenchmarkResult, len(stores))
+	for i, store := range stores {
+		for _, bench := range []func(b *testing.B){store.insert, store.search, store.searchPrefix} {
+			var result testing.BenchmarkResult
+			if bench != nil {
+				result = testing.Benchmark(bench)
+			}
+			timings[i] = append(timings[i], result)
+		}
+	}
+	return timings
+}
+
+// timingTable formats the timings as a markdown table, one row per store
+func timingTable(stores []comparedStore, timings [][]testing.BenchmarkResult) string {
+	var table strings.Builder
+	table.WriteString("Time and allocations per iteration of each store's Realistic benchmark, which is one pass over the realistic keys. " +
+		"Blank cells are stores without that operation (or without a benchmark for it).\n\n")
+	table.WriteString("| Store |")
+	for _, op := range benchmarkOps {
+		fmt.Fprintf(&table, " %s |", op)
+	}
+	table.WriteString("\n|---|")
+	for range benchmarkOps {
+		table.WriteString("---|")
+	}
+	for i, store := range stores {
+		fmt.Fprintf(&table, "\n| %s |", store.name)
+		for _, result := range timings[i] {
+			if result.N == 0 {
+				table.WriteString(" |")
+				continue
+			}
+			fmt.Fprintf(&table, " %d ns / %d allocs |", result.NsPerOp(), result.AllocsPerOp())
+		}
+	}
+	table.WriteString("\n")
+	return table.String()
+}
+
+// conclusions sums up the timings relative to the first store (the map): the fastest and slowest store for every
+// operation, and how the chunked trie (what this repo set out to try) compares. They're worked out from the same
+// run as the tables, so they can't disagree with them.
+func conclusions(stores []comparedStore, timings [][]testing.BenchmarkResult) string {
+	var lines strings.Builder
+	fmt.Fprintf(&lines, "Times relative to the %s's, from the time per pass table below.\n\n", strings.ToLower(stores[0].name))
+	relative := func(i, op int) float64 {
+		return float64(timings[i][op].NsPerOp()) / float64(timings[0][op].NsPerOp())
+	}
+	for op, name := range benchmarkOps {
+		fastest, slowest := -1, -1
+		for i := range stores {
+			if timings[i][op].N == 0 {
+				continue
+			}
+			if fastest < 0 || timings[i][op].NsPerOp() < timings[fastest][op].NsPerOp() {
+				fastest = i
+			}
+			if slowest < 0 || timings[i][op].NsPerOp() > timings[slowest][op].NsPerOp() {
+				slowest = i
+			}
+		}
+		fmt.Fprintf(&lines, "- %s: fastest is the %s (%.1fx), slowest the %s (%.1fx)\n",
+			name, stores[fastest].name, relative(fastest, op), stores[slowest].name, relative(slowest, op))
+	}
+	for i, store := range stores {
+		if store.name != "Chunked Prefix Trie" {
+			continue
+		}
+		fmt.Fprintf(&lines, "- the %s takes %.1fx the time on Insert, %.1fx on Search and %.1fx on SearchPrefix\n",
+			store.name, relative(i, 0), relative(i, 1), relative(i, 2))
+	}
+	return lines.String()
+}
+
+// benchmarkSection is the README's benchmark section: the conclusions and the two tables
+func benchmarkSection(conclusions, timings, footprints string) string {
+	var section strings.Builder
+	fmt.Fprintf(&section, "%s (updated %s)\n\n", benchmarkHeading, time.Now().Format("Jan 2, 2006"))
+	fmt.Fprintf(&section, "Generated by `go test -run Compare -compare -compare.readme` (`-compare.keys=N` to change the number of keys in the "+
+		"memory footprint table) on %s/%s with %s.\n\n", runtime.GOOS, runtime.GOARCH, runtime.Version())
+	section.WriteString("### Conclusions\n\n" + conclusions + "\n### Time per pass\n\n" + timings + "\n### Memory footprint\n\n" + footprints)
+	return section.String()
+}
+
+// replaceBenchmarkSection swaps the README's benchmark section for section
+func replaceBenchmarkSection(readme, section string) (string, error) {
+	start := strings.Index(readme, "\n"+benchmarkHeading)
+	if start < 0 {
+		return "", fmt.Errorf("README.md has no %q section", benchmarkHeading)
+	}
+	start++
+	end := len(readme)
+	if next := strings.Index(readme[start+len(benchmarkHeading):], "\n## "); next >= 0 {
+		end = start + len(benchmarkHeading) + next + 1
+		section += "\n"
+	}
+	return readme[:start] + section + readme[end:], nil
+}
+
+func TestCompare(t *testing.T) {
+	if !*compare {
+		t.Skip("run with -compare")
+	}
+	datasets := footprintDatasets(*compareKeys)
+	var results []footprintResult
+	for _, store := range comparedStores {
+		for _, dataset := range datasets {
+			retained, gcTime := measureFootprint(store, dataset.keys)
+			results = append(results, footprintResult{store.name, dataset.name, len(dataset.keys), retained, gcTime})
+		}
+	}
+	timings := runTimings(comparedStores)
+	section := benchmarkSection(conclusions(comparedStores, timings), timingTable(comparedStores, timings), footprintTable(datasets, results))
+	fmt.Print(section)
+
+	if !*compareReadme {
+		return
+	}
+	readme, err := os.ReadFile("README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, err := replaceBenchmarkSection(string(readme), section)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("README.md", []byte(updated), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReplaceBenchmarkSection(t *testing.T) {
+	readme := "# Title\n\n## Benchmarks (updated Nov 9, 2023)\n\nold\n\n## After\n\nkept\n"
+	updated, err := replaceBenchmarkSection(readme, "## Benchmarks (updated today)\n\nnew\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "# Title\n\n## Benchmarks (updated today)\n\nnew\n\n## After\n\nkept\n"; updated != expected {
+		t.Errorf("expected %q, got %q", expected, updated)
+	}
+	// at the end of the file, everything after the heading goes
+	updated, _ = replaceBenchmarkSection("# Title\n\n## Benchmarks\n\nold\n### Sub\n", "## Benchmarks\n\nnew\n")
+	if expected := "# Title\n\n## Benchmarks\n\nnew\n"; updated != expected {
+		t.Errorf("expected %q, got %q", expected, updated)
+	}
+	if _, err := replaceBenchmarkSection("# Title\n", "new"); err == nil {
+		t.Errorf("expected an error without a benchmark section")
+	}
+}
+
+func TestConclusions(t *testing.T) {
+	stores := []comparedStore{{name: "Map"}, {name: "Chunked Prefix Trie"}, {name: "Read Only"}}
+	ns := func(n time.Duration) testing.BenchmarkResult { return testing.BenchmarkResult{N: 1, T: n} }
+	timings := [][]testing.BenchmarkResult{
+		{ns(100), ns(100), ns(1000)},
+		{ns(400), ns(300), ns(100)},
+		{{}, ns(50), ns(2000)},
+	}
+	expected := "Times relative to the map's, from the time per pass table below.\n\n" +
+		"- Insert: fastest is the Map (1.0x), slowest the Chunked Prefix Trie (4.0x)\n" +
+		"- Search: fastest is the Read Only (0.5x), slowest the Chunked Prefix Trie (3.0x)\n" +
+		"- SearchPrefix: fastest is the Chunked Prefix Trie (0.1x), slowest the Read Only (2.0x)\n" +
+		"- the Chunked Prefix Trie takes 4.0x the time on Insert, 3.0x on Search and 0.1x on SearchPrefix\n"
+	if got := conclusions(stores, timings); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
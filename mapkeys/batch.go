@@ -0,0 +1,22 @@
+package mapkeys
+
+import "github.com/groovemonkey/trie-keys-experiment/batch"
+
+// Apply applies every operation in the batch, in the order they were queued.
+// Individual operations can't fail, so a batch is always applied in full, and nothing reading the store
+// can run in between them. Like every other write, Apply must not run concurrently with other goroutines.
+func (s *Store[T]) Apply(b *batch.Batch[T]) {
+	b.Each(func(key string, val T, isDelete bool) {
+		if isDelete {
+			delete(*s, key)
+		} else {
+			(*s)[key] = val
+		}
+	})
+}
+
+// Begin starts an optimistic transaction against the store. The map has nowhere to keep versions, so the
+// transaction compares values instead: a key that changed and then changed back doesn't count as a conflict.
+func (s *Store[T]) Begin() *batch.Txn[T] {
+	return batch.BeginByValue(s.Search, s.Apply)
+}
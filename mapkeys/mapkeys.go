@@ -2,6 +2,7 @@ package mapkeys
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"golang.org/x/exp/constraints"
@@ -22,6 +23,13 @@ func (s *Store[T]) Insert(key string, value T) {
 	(*s)[key] = value
 }
 
+// Delete removes key from the store and reports whether it was there
+func (s *Store[T]) Delete(key string) bool {
+	_, ok := (*s)[key]
+	delete(*s, key)
+	return ok
+}
+
 func (s *Store[T]) Search(key string) (bool, T) {
 	var defaultResult T
 	val, ok := (*s)[key]
@@ -64,13 +72,124 @@ func Sum[T Number](keysAndVals map[string]T) T {
 	return sum
 }
 
+// KeyValue is a single key and its value, used wherever results need a stable order
+type KeyValue[T Number] struct {
+	Key   string
+	Value T
+}
+
+// sortedKeys returns all keys in lexicographic (byte) order.
+// Go maps have no order, so this is O(n log n) every time it's called.
+func (s *Store[T]) sortedKeys() []string {
+	keys := make([]string, 0, len(*s))
+	for key := range *s {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Each calls fn for every key in lexicographic order, stopping early if fn returns false
+func (s *Store[T]) Each(fn func(key string, val T) bool) {
+	for _, key := range s.sortedKeys() {
+		if !fn(key, (*s)[key]) {
+			return
+		}
+	}
+}
+
+// Range returns all keys k with startKey <= k < endKey, in lexicographic order.
+// An empty endKey means "no upper bound".
+func (s *Store[T]) Range(startKey, endKey string) []KeyValue[T] {
+	var results []KeyValue[T]
+	for c := s.Seek(startKey); c.Valid(); c.Next() {
+		if endKey != "" && c.Key() >= endKey {
+			break
+		}
+		results = append(results, KeyValue[T]{Key: c.Key(), Value: c.Value()})
+	}
+	return results
+}
+
+// Cursor walks the store's keys in lexicographic order.
+// It works on a snapshot of the keys taken by Seek, so keys inserted afterwards aren't visited.
+type Cursor[T Number] struct {
+	store *Store[T]
+	keys  []string
+	pos   int
+}
+
+// Seek returns a Cursor positioned at the first key >= key
+func (s *Store[T]) Seek(key string) *Cursor[T] {
+	keys := s.sortedKeys()
+	return &Cursor[T]{store: s, keys: keys, pos: sort.SearchStrings(keys, key)}
+}
+
+// Valid reports whether the cursor points at a key (false once it has run off the end)
+func (c *Cursor[T]) Valid() bool {
+	return c.pos < len(c.keys)
+}
+
+// Next moves the cursor to the next key
+func (c *Cursor[T]) Next() {
+	if c.Valid() {
+		c.pos++
+	}
+}
+
+// Key returns the key the cursor points at
+func (c *Cursor[T]) Key() string {
+	return c.keys[c.pos]
+}
+
+// Value returns the current value stored for the key the cursor points at
+func (c *Cursor[T]) Value() T {
+	return (*c.store)[c.keys[c.pos]]
+}
+
 func (s *Store[T]) String() string {
 	var resultString string
 	if s == nil {
 		return ""
 	}
-	for key, val := range *s {
+	s.Each(func(key string, val T) bool {
 		resultString = resultString + fmt.Sprintf("\n%s - %v", key, val)
-	}
+		return true
+	})
 	return resultString
 }
+
+// Update sets key to whatever fn returns, given the current value (and whether there is one)
+func (s *Store[T]) Update(key string, fn func(old T, exists bool) T) T {
+	old, exists := (*s)[key]
+	newVal := fn(old, exists)
+	(*s)[key] = newVal
+	return newVal
+}
+
+// Increment adds delta to the value for key (starting from zero if there isn't one) and returns the new value
+func (s *Store[T]) Increment(key string, delta T) T {
+	(*s)[key] += delta
+	return (*s)[key]
+}
+
+// CompareAndSwap sets key to new only if it currently holds old, and reports whether it did.
+// Missing keys are never swapped.
+func (s *Store[T]) CompareAndSwap(key string, old, new T) bool {
+	current, ok := (*s)[key]
+	if !ok || current != old {
+		return false
+	}
+	(*s)[key] = new
+	return true
+}
+
+// LoadOrStore returns the existing value for key if there is one (and loaded=true).
+// Otherwise it stores val and returns it.
+func (s *Store[T]) LoadOrStore(key string, val T) (actual T, loaded bool) {
+	if current, ok := (*s)[key]; ok {
+		return current, true
+	}
+	(*s)[key] = val
+	return val, false
+}
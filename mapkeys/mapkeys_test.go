@@ -1,7 +1,10 @@
 package mapkeys
 
 import (
+	"reflect"
 	"testing"
+
+	"github.com/groovemonkey/trie-keys-experiment/batch"
 )
 
 func TestInsert(t *testing.T) {
@@ -103,3 +106,146 @@ func TestAggregateDescendants(t *testing.T) {
 		t.Errorf("expected valid result of -2, got %v", sum)
 	}
 }
+
+func TestOrderedIteration(t *testing.T) {
+	store := make(Store[int64])
+	store.Insert("b", 2)
+	store.Insert("a.b", 1)
+	store.Insert("c", 3)
+	store.Insert("a", 0)
+
+	var keys []string
+	store.Each(func(key string, val int64) bool {
+		keys = append(keys, key)
+		return true
+	})
+	expected := []string{"a", "a.b", "b", "c"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected keys in order %v, got %v", expected, keys)
+	}
+
+	results := store.Range("a.b", "c")
+	if len(results) != 2 || results[0].Key != "a.b" || results[1].Key != "b" {
+		t.Errorf("expected Range to return [a.b b], got %v", results)
+	}
+
+	c := store.Seek("a.a")
+	if !c.Valid() || c.Key() != "a.b" || c.Value() != 1 {
+		t.Errorf("expected Seek to land on a.b")
+	}
+	c.Next()
+	c.Next()
+	c.Next()
+	if c.Valid() {
+		t.Errorf("expected cursor to run off the end, but it's at %s", c.Key())
+	}
+}
+
+func TestSearchPrefixPage(t *testing.T) {
+	store := make(Store[int64])
+	for i, key := range []string{"a", "b", "ba", "bb", "bc", "bd", "c"} {
+		store.Insert(key, int64(i))
+	}
+
+	page, token := store.SearchPrefixPage("b", PageOptions{Limit: 2})
+	if len(page) != 2 || page[0].Key != "b" || page[1].Key != "ba" || token != "ba" {
+		t.Errorf("expected first page [b ba], got %v (token %s)", page, token)
+	}
+	page, token = store.SearchPrefixPage("b", PageOptions{Limit: 2, After: token, Offset: 1})
+	if len(page) != 2 || page[0].Key != "bc" || page[1].Key != "bd" || token != "" {
+		t.Errorf("expected last page [bc bd], got %v (token %s)", page, token)
+	}
+
+	var keys []string
+	store.SearchPrefixFunc("b", func(key string, val int64) bool {
+		keys = append(keys, key)
+		return len(keys) < 3
+	})
+	if !reflect.DeepEqual(keys, []string{"b", "ba", "bb"}) {
+		t.Errorf("expected SearchPrefixFunc to stop after 3 keys, got %v", keys)
+	}
+}
+
+func TestReadModifyWrite(t *testing.T) {
+	store := make(Store[int64])
+
+	if val := store.Increment("counter", 2); val != 2 {
+		t.Errorf("expected Increment on a missing key to start from 0, got %d", val)
+	}
+	store.Increment("counter", 3)
+
+	val := store.Update("counter", func(old int64, exists bool) int64 {
+		if !exists {
+			t.Errorf("expected Update to see the existing value")
+		}
+		return old * 10
+	})
+	if val != 50 {
+		t.Errorf("expected Update to return 50, got %d", val)
+	}
+
+	if store.CompareAndSwap("counter", 1, 2) {
+		t.Errorf("expected CompareAndSwap with the wrong old value to fail")
+	}
+	if !store.CompareAndSwap("counter", 50, 60) {
+		t.Errorf("expected CompareAndSwap with the right old value to succeed")
+	}
+	if store.CompareAndSwap("missing", 0, 1) {
+		t.Errorf("expected CompareAndSwap on a missing key to fail")
+	}
+
+	actual, loaded := store.LoadOrStore("counter", 1)
+	if !loaded || actual != 60 {
+		t.Errorf("expected LoadOrStore to load 60, got %d (loaded=%v)", actual, loaded)
+	}
+	actual, loaded = store.LoadOrStore("fresh", 1)
+	if loaded || actual != 1 {
+		t.Errorf("expected LoadOrStore to store 1, got %d (loaded=%v)", actual, loaded)
+	}
+}
+
+func TestBatchAndTxn(t *testing.T) {
+	store := make(Store[int64])
+	store.Insert("profits.revenue.net", 3)
+	store.Insert("profits.revenue.taxes", -200)
+
+	var b batch.Batch[int64]
+	b.Insert("profits.revenue.net", 4)
+	b.Delete("profits.revenue.taxes")
+	store.Apply(&b)
+	if found, _ := store.Search("profits.revenue.taxes"); found || len(store) != 1 {
+		t.Errorf("expected the batch to delete taxes, store=%v", store)
+	}
+
+	tx := store.Begin()
+	_, net := tx.Get("profits.revenue.net")
+	tx.Insert("profits.revenue.net", net+1)
+	if err := tx.Commit(); err != nil {
+		t.Errorf("expected Commit to succeed, got %v", err)
+	}
+
+	tx = store.Begin()
+	tx.Get("profits.revenue.net")
+	tx.Insert("profits.revenue.total", 100)
+	store.Insert("profits.revenue.net", 6)
+	if err := tx.Commit(); err != batch.ErrConflict {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	if found, _ := store.Search("profits.revenue.total"); found {
+		t.Errorf("expected a conflicting transaction to write nothing")
+	}
+}
+
+func TestStats(t *testing.T) {
+	store := make(Store[int64])
+	store.Insert("profits.revenue.net", 3)
+	store.Insert("profits.revenue.taxes", -200)
+
+	stats := store.Stats()
+	if stats.Keys != 2 || stats.Nodes != 0 || stats.StringBytes != len("profits.revenue.net")+len("profits.revenue.taxes") {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if stats.ValueBytes != 16 || stats.ChildrenBytes <= 0 || stats.Bytes() <= stats.StringBytes+stats.ValueBytes {
+		t.Errorf("expected the map's table to be counted, got %+v", stats)
+	}
+}
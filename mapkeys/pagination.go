@@ -0,0 +1,64 @@
+package mapkeys
+
+import (
+	"sort"
+	"strings"
+)
+
+// PageOptions controls which slice of a prefix search SearchPrefixPage returns
+type PageOptions struct {
+	// maximum number of results to return, 0 means no limit
+	Limit int
+	// number of matching keys to skip before collecting results
+	Offset int
+	// continuation token returned by a previous page: only keys after it are returned
+	After string
+}
+
+// sortedKeysWithPrefix returns the keys with the given prefix in lexicographic order
+func (s *Store[T]) sortedKeysWithPrefix(prefix string) []string {
+	var keys []string
+	// we still have to iterate over EVERYTHING
+	for key := range *s {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SearchPrefixFunc calls fn for every key with the given prefix in lexicographic order, stopping early if fn returns false
+func (s *Store[T]) SearchPrefixFunc(prefix string, fn func(key string, val T) bool) {
+	for _, key := range s.sortedKeysWithPrefix(prefix) {
+		if !fn(key, (*s)[key]) {
+			return
+		}
+	}
+}
+
+// SearchPrefixPage returns one sorted page of the keys with the given prefix, and a continuation token for the next page.
+// The token is empty when there are no more results. Pass it back in PageOptions.After to continue.
+func (s *Store[T]) SearchPrefixPage(prefix string, opts PageOptions) ([]KeyValue[T], string) {
+	keys := s.sortedKeysWithPrefix(prefix)
+	start := 0
+	if opts.After != "" {
+		start = sort.Search(len(keys), func(i int) bool { return keys[i] > opts.After })
+	}
+	start += opts.Offset
+	if start >= len(keys) {
+		return nil, ""
+	}
+	keys = keys[start:]
+
+	nextToken := ""
+	if opts.Limit > 0 && len(keys) > opts.Limit {
+		keys = keys[:opts.Limit]
+		nextToken = keys[len(keys)-1]
+	}
+	results := make([]KeyValue[T], 0, len(keys))
+	for _, key := range keys {
+		results = append(results, KeyValue[T]{Key: key, Value: (*s)[key]})
+	}
+	return results, nextToken
+}
@@ -0,0 +1,19 @@
+package mapkeys
+
+import (
+	"unsafe"
+
+	"github.com/groovemonkey/trie-keys-experiment/stats"
+)
+
+// Stats returns the store's Stats. A map is flat, so it only has keys, the map's table, strings and values.
+func (s *Store[T]) Stats() stats.Stats {
+	var zero T
+	st := stats.Stats{Keys: len(*s)}
+	for key := range *s {
+		st.StringBytes += len(key)
+	}
+	st.ValueBytes = st.Keys * int(unsafe.Sizeof(zero))
+	st.ChildrenBytes = stats.MapBytes(st.Keys, unsafe.Sizeof("")+unsafe.Sizeof(zero)) - st.ValueBytes
+	return st
+}
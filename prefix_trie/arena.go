@@ -0,0 +1,186 @@
+package prefix_trie
+
+import "unicode/utf8"
+
+// ArenaTrie is a Trie whose nodes all live in one big slice and point at each other by index instead of
+// by pointer, and whose children are found through one trie-wide map from (parent, rune) to child.
+// Neither the slice nor the map contain pointers (as long as T doesn't), so the garbage collector doesn't
+// have to look inside them: millions of keys cost it about as much as a handful of allocations.
+// Deleted nodes go on a free list and are reused by later inserts.
+//
+// Nodes move when the slice grows, so unlike Trie it hands out values rather than node pointers.
+//
+// It's a separate type for large, mostly static key sets whose GC cost matters, not a storage mode of Trie:
+// it only has Insert, Search, Delete, SearchPrefix, Len and Stats. Walk, Rank/Select, Seek, Update, Batch
+// and pagination are Trie-only, so use a Trie if you need them.
+type ArenaTrie[T any] struct {
+	// nodes[0] is the root
+	nodes    []arenaNode[T]
+	children map[arenaEdge]uint32
+	// first node on the free list, 0 if it's empty (the root is never freed, so 0 is never a free node)
+	free    uint32
+	numKeys int
+}
+
+type arenaNode[T any] struct {
+	Char  rune
+	Value T
+	// avoid mistaking initialized zero values for intentional zero values
+	HasValue bool
+	parent   uint32
+	// children form a doubly linked list so they can be walked (for SearchPrefix) and unlinked (for Delete).
+	// 0 means none. Free nodes are linked through nextSibling.
+	firstChild  uint32
+	nextSibling uint32
+	prevSibling uint32
+}
+
+type arenaEdge struct {
+	parent uint32
+	char   rune
+}
+
+func NewArena[T any]() *ArenaTrie[T] {
+	return &ArenaTrie[T]{nodes: make([]arenaNode[T], 1), children: make(map[arenaEdge]uint32)}
+}
+
+// Len returns the number of keys
+func (t *ArenaTrie[T]) Len() int {
+	return t.numKeys
+}
+
+// NumNodes returns the number of nodes in use, including the root
+func (t *ArenaTrie[T]) NumNodes() int {
+	free := 0
+	for i := t.free; i != 0; i = t.nodes[i].nextSibling {
+		free++
+	}
+	return len(t.nodes) - free
+}
+
+// newNode takes a node off the free list, or appends one
+func (t *ArenaTrie[T]) newNode(parent uint32, char rune) uint32 {
+	i := t.free
+	if i != 0 {
+		t.free = t.nodes[i].nextSibling
+		t.nodes[i] = arenaNode[T]{}
+	} else {
+		i = uint32(len(t.nodes))
+		t.nodes = append(t.nodes, arenaNode[T]{})
+	}
+	n := &t.nodes[i]
+	n.Char = char
+	n.parent = parent
+	// link it in at the front of the parent's children
+	p := &t.nodes[parent]
+	n.nextSibling = p.firstChild
+	if p.firstChild != 0 {
+		t.nodes[p.firstChild].prevSibling = i
+	}
+	p.firstChild = i
+	t.children[arenaEdge{parent, char}] = i
+	return i
+}
+
+// freeNode unlinks a node that has no value and no children, and puts it on the free list
+func (t *ArenaTrie[T]) freeNode(i uint32) {
+	n := &t.nodes[i]
+	if n.prevSibling != 0 {
+		t.nodes[n.prevSibling].nextSibling = n.nextSibling
+	} else {
+		t.nodes[n.parent].firstChild = n.nextSibling
+	}
+	if n.nextSibling != 0 {
+		t.nodes[n.nextSibling].prevSibling = n.prevSibling
+	}
+	delete(t.children, arenaEdge{n.parent, n.Char})
+	*n = arenaNode[T]{nextSibling: t.free}
+	t.free = i
+}
+
+// find returns the index of the node for key, or false
+func (t *ArenaTrie[T]) find(key string) (uint32, bool) {
+	var i uint32
+	for _, char := range key {
+		child, ok := t.children[arenaEdge{i, char}]
+		if !ok {
+			return 0, false
+		}
+		i = child
+	}
+	return i, true
+}
+
+func (t *ArenaTrie[T]) Insert(key string, val T) {
+	var i uint32
+	for _, char := range key {
+		child, ok := t.children[arenaEdge{i, char}]
+		if !ok {
+			child = t.newNode(i, char)
+		}
+		i = child
+	}
+	n := &t.nodes[i]
+	if !n.HasValue {
+		t.numKeys++
+	}
+	n.Value = val
+	n.HasValue = true
+}
+
+// Search returns whether key exists (with a value), and its value
+func (t *ArenaTrie[T]) Search(key string) (bool, T) {
+	var zero T
+	i, ok := t.find(key)
+	if !ok || !t.nodes[i].HasValue {
+		return false, zero
+	}
+	return true, t.nodes[i].Value
+}
+
+// Delete removes key from the trie and reports whether it was there.
+// Nodes that no longer lead to any key are freed for reuse.
+func (t *ArenaTrie[T]) Delete(key string) bool {
+	i, ok := t.find(key)
+	if !ok || !t.nodes[i].HasValue {
+		return false
+	}
+	var zero T
+	t.nodes[i].Value = zero
+	t.nodes[i].HasValue = false
+	t.numKeys--
+	for i != 0 && !t.nodes[i].HasValue && t.nodes[i].firstChild == 0 {
+		parent := t.nodes[i].parent
+		t.freeNode(i)
+		i = parent
+	}
+	return true
+}
+
+// SearchPrefix returns all keys with the given prefix, mapped to their values
+func (t *ArenaTrie[T]) SearchPrefix(prefix string) map[string]T {
+	keysAndVals := make(map[string]T)
+	if len(prefix) == 0 {
+		return keysAndVals
+	}
+	i, ok := t.find(prefix)
+	if !ok {
+		return keysAndVals
+	}
+	t.each(i, newKeyBuffer(prefix), func(key []byte, val T) {
+		keysAndVals[string(key)] = val
+	})
+	return keysAndVals
+}
+
+// each calls fn for every key at or below node i, whose key is in key (a reused buffer)
+func (t *ArenaTrie[T]) each(i uint32, key []byte, fn func(key []byte, val T)) []byte {
+	if t.nodes[i].HasValue {
+		fn(key, t.nodes[i].Value)
+	}
+	keyLen := len(key)
+	for child := t.nodes[i].firstChild; child != 0; child = t.nodes[child].nextSibling {
+		key = t.each(child, utf8.AppendRune(key[:keyLen], t.nodes[child].Char), fn)
+	}
+	return key[:keyLen]
+}
@@ -0,0 +1,32 @@
+package prefix_trie
+
+import "github.com/groovemonkey/trie-keys-experiment/batch"
+
+// Apply applies every operation in the batch, in the order they were queued.
+// Individual operations can't fail, so a batch is always applied in full. Apply doesn't lock, though: like every
+// other write it needs exclusive access to the trie, and readers only see the batch all at once (never half of it)
+// if they share a lock with it, e.g. a sync.RWMutex held for writing around Apply and for reading around searches.
+func (t *Trie[T]) Apply(b *batch.Batch[T]) {
+	b.Each(func(key string, val T, isDelete bool) {
+		if isDelete {
+			t.Delete(key)
+		} else {
+			t.Insert(key, val)
+		}
+	})
+}
+
+// Begin starts an optimistic transaction against the trie. Its Commit needs the same exclusive access as Apply.
+func (t *Trie[T]) Begin() *batch.Txn[T] {
+	return batch.Begin(t.readVersion, t.Apply)
+}
+
+// readVersion returns whether key exists, its value and the version of that value, for transactions
+func (t *Trie[T]) readVersion(key string) (bool, T, uint64) {
+	found, node := t.Search(key)
+	if !found || !node.HasValue {
+		var zero T
+		return false, zero, 0
+	}
+	return true, node.Value, node.version
+}
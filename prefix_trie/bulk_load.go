@@ -0,0 +1,120 @@
+package prefix_trie
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// ErrUnsorted is returned by NewFromSorted when keys aren't strictly increasing in lexicographic order
+var ErrUnsorted = errors.New("keys must be strictly increasing")
+
+// how many nodes the bulk loader allocates at a time
+const slabSize = 256
+
+// builder holds the state of a bulk load: the path of nodes from the root to the last key (none of which are
+// finished, since later keys can still add children to them), and the finished nodes waiting for their parent
+type builder[T any] struct {
+	path []*trieNode[T]
+	// childStart[d] is where path[d]'s finished children start in pending
+	childStart []int
+	pending    []*trieNode[T]
+	// nodes are carved out of slabs instead of being allocated one by one
+	slab []trieNode[T]
+}
+
+// NewFromSorted builds a trie from a stream of keys in lexicographic order, with far fewer allocations than calling Insert for each key.
+// next returns the next key and value, and false once the stream is done.
+// Because the input is sorted, a node is finished as soon as a key leaves its subtree, so its children map
+// is allocated once at exactly the right size, and leaves don't get one at all.
+func NewFromSorted[T any](next func() (key string, val T, ok bool)) (*Trie[T], error) {
+	t := New[T]()
+	b := &builder[T]{path: []*trieNode[T]{t.root}, childStart: []int{0}}
+	var prevKey string
+	started := false
+
+	for key, val, ok := next(); ok; key, val, ok = next() {
+		if started && key <= prevKey {
+			return nil, fmt.Errorf("%w: %q came after %q", ErrUnsorted, key, prevKey)
+		}
+		prevKey, started = key, true
+
+		// follow the part of the current path this key shares
+		// (the key's own node can't be on the path: it would have to sort before the previous key)
+		depth, offset := 0, 0
+		for offset < len(key) && depth+1 < len(b.path) {
+			char, size := utf8.DecodeRuneInString(key[offset:])
+			if b.path[depth+1].Char != char {
+				break
+			}
+			depth++
+			offset += size
+		}
+		// everything deeper than that is finished
+		for len(b.path)-1 > depth {
+			b.finishTop()
+		}
+		// and the rest of the key is new
+		for _, char := range key[offset:] {
+			b.push(char)
+		}
+		node := b.path[len(b.path)-1]
+		node.Value = val
+		node.HasValue = true
+		t.touch(node)
+	}
+	for len(b.path) > 1 {
+		b.finishTop()
+	}
+	b.finish(t.root, b.pending)
+	return t, nil
+}
+
+// NewFromSortedSlice builds a trie from key/value pairs in lexicographic order, see NewFromSorted
+func NewFromSortedSlice[T any](kvs []KeyValue[T]) (*Trie[T], error) {
+	i := 0
+	return NewFromSorted(func() (string, T, bool) {
+		if i == len(kvs) {
+			var zero T
+			return "", zero, false
+		}
+		i++
+		return kvs[i-1].Key, kvs[i-1].Value, true
+	})
+}
+
+// push opens a new node at the end of the path
+func (b *builder[T]) push(char rune) {
+	if len(b.slab) == 0 {
+		b.slab = make([]trieNode[T], slabSize)
+	}
+	node := &b.slab[0]
+	b.slab = b.slab[1:]
+	node.Char = char
+	b.path = append(b.path, node)
+	b.childStart = append(b.childStart, len(b.pending))
+}
+
+// finishTop finishes the last node on the path and hands it to its parent
+func (b *builder[T]) finishTop() {
+	top := len(b.path) - 1
+	node, start := b.path[top], b.childStart[top]
+	b.finish(node, b.pending[start:])
+	b.pending = append(b.pending[:start], node)
+	b.path, b.childStart = b.path[:top], b.childStart[:top]
+}
+
+// finish fills in a node's children map and KeyCount once all of its children are known
+func (b *builder[T]) finish(node *trieNode[T], children []*trieNode[T]) {
+	if node.HasValue {
+		node.KeyCount = 1
+	}
+	if len(children) == 0 {
+		return
+	}
+	node.Children = make(map[rune]*trieNode[T], len(children))
+	for _, child := range children {
+		node.Children[child.Char] = child
+		node.KeyCount += child.KeyCount
+	}
+}
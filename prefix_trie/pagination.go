@@ -0,0 +1,56 @@
+package prefix_trie
+
+import "strings"
+
+// PageOptions controls which slice of a prefix search SearchPrefixPage returns
+type PageOptions struct {
+	// maximum number of results to return, 0 means no limit
+	Limit int
+	// number of matching keys to skip before collecting results
+	Offset int
+	// continuation token returned by a previous page: only keys after it are returned
+	After string
+}
+
+// SearchPrefixFunc calls fn for every key with the given prefix in lexicographic order, stopping early if fn returns false.
+// Unlike SearchPrefix, nothing is materialized, and an empty prefix matches every key.
+func (t *Trie[T]) SearchPrefixFunc(prefix string, fn func(key string, val T) bool) {
+	for c := t.Seek(prefix); c.Valid(); c.Next() {
+		key := c.Key()
+		if !strings.HasPrefix(key, prefix) || !fn(key, c.Value()) {
+			return
+		}
+	}
+}
+
+// SearchPrefixPage returns one sorted page of the keys with the given prefix, and a continuation token for the next page.
+// The token is empty when there are no more results. Pass it back in PageOptions.After to continue.
+func (t *Trie[T]) SearchPrefixPage(prefix string, opts PageOptions) ([]KeyValue[T], string) {
+	// use the order statistics to jump straight to the first result instead of walking past skipped keys
+	start := t.Rank(prefix)
+	if opts.After != "" {
+		after := t.Rank(opts.After)
+		if t.hasKey(opts.After) {
+			after++
+		}
+		start = max(start, after)
+	}
+	found, first := t.Select(start + opts.Offset)
+	if !found {
+		return nil, ""
+	}
+
+	var results []KeyValue[T]
+	for c := t.Seek(first.Key); c.Valid(); c.Next() {
+		key := c.Key()
+		if !strings.HasPrefix(key, prefix) {
+			break
+		}
+		// there's at least one more result, so hand out a token
+		if opts.Limit > 0 && len(results) == opts.Limit {
+			return results, results[len(results)-1].Key
+		}
+		results = append(results, KeyValue[T]{Key: key, Value: c.Value()})
+	}
+	return results, ""
+}
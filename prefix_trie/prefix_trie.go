@@ -1,17 +1,26 @@
 package prefix_trie
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+)
 
 type trieNode[T any] struct {
 	Char  rune
 	Value T
 	// avoid mistaking initialized zero values for intentional zero values
 	HasValue bool
+	// number of keys stored in this node's subtree, including this node itself
+	KeyCount int
+	// stamped from Trie.version on every write to this node's value, so transactions can tell whether a key changed
+	version  uint64
 	Children map[rune]*trieNode[T]
 }
 
 type Trie[T any] struct {
 	root *trieNode[T]
+	// bumped on every write
+	version uint64
 }
 
 func New[T any]() *Trie[T] {
@@ -19,30 +28,108 @@ func New[T any]() *Trie[T] {
 }
 
 func (t *Trie[T]) Insert(s string, val T) {
+	// collect the path on the stack so KeyCounts can be fixed up without walking the key twice
+	var pathBuf [64]*trieNode[T]
+	node, path := t.findOrCreate(s, pathBuf[:0])
+	t.setValue(path, node, val)
+}
+
+// findOrCreate walks to the node for key, creating any missing nodes on the way.
+// It appends every node on the path to path, from the root down to the key's node.
+func (t *Trie[T]) findOrCreate(key string, path []*trieNode[T]) (*trieNode[T], []*trieNode[T]) {
 	currentNode := t.root
-	for i, char := range s {
+	path = append(path, currentNode)
+	for _, char := range key {
 		child, ok := currentNode.Children[char]
 		// If there's no such child, create one
 		if !ok {
 			child = &trieNode[T]{Char: char, Children: make(map[rune]*trieNode[T])}
+			// bulk-loaded leaves don't get a children map until they need one
+			if currentNode.Children == nil {
+				currentNode.Children = make(map[rune]*trieNode[T])
+			}
 			currentNode.Children[char] = child
 		}
-		// is this a leaf node? If so, set the value.
-		if i == (len(s) - 1) {
-			child.Value = val
-			child.HasValue = true
-		}
 		// set currentNode for the next iteration
 		currentNode = child
+		path = append(path, currentNode)
+	}
+	// we end up at the last rune of the key
+	// NOTE: callers set the value after the loop because the last rune's byte offset isn't len(s)-1 for multi-byte runes
+	return currentNode, path
+}
+
+// findPath appends every node on the path to key to path, from the root down to the key's node.
+// It returns nil if there's no node for key.
+func (t *Trie[T]) findPath(key string, path []*trieNode[T]) []*trieNode[T] {
+	currentNode := t.root
+	path = append(path, currentNode)
+	for _, char := range key {
+		child, ok := currentNode.Children[char]
+		if !ok {
+			return nil
+		}
+		currentNode = child
+		path = append(path, currentNode)
+	}
+	return path
+}
+
+// setValue sets the value of the node at the end of path, counting it as a new key on the way down if it didn't have one
+func (t *Trie[T]) setValue(path []*trieNode[T], node *trieNode[T], val T) {
+	if !node.HasValue {
+		for _, pathNode := range path {
+			pathNode.KeyCount++
+		}
+	}
+	node.Value = val
+	node.HasValue = true
+	t.touch(node)
+}
+
+// touch records a write to node's value
+func (t *Trie[T]) touch(node *trieNode[T]) {
+	t.version++
+	node.version = t.version
+}
+
+// Delete removes key from the trie and reports whether it was there.
+// Nodes that no longer lead to any key are pruned.
+func (t *Trie[T]) Delete(key string) bool {
+	var pathBuf [64]*trieNode[T]
+	path := t.findPath(key, pathBuf[:0])
+	if path == nil {
+		return false
+	}
+	node := path[len(path)-1]
+	if !node.HasValue {
+		return false
 	}
+	var zero T
+	node.Value = zero
+	node.HasValue = false
+	t.touch(node)
+	for _, pathNode := range path {
+		pathNode.KeyCount--
+	}
+	// cut off the highest node on the path that no longer has any keys below it (never the root)
+	for i := 1; i < len(path); i++ {
+		if path[i].KeyCount == 0 {
+			delete(path[i-1].Children, path[i].Char)
+			break
+		}
+	}
+	return true
 }
 
 func (t *Trie[T]) DepthFirstPrint() {
 	if t.root == nil {
 		return
 	}
-	currentNode := t.root
-	depthFirstPrint(currentNode, "")
+	// the root node doesn't hold a rune, so start with its children
+	for _, node := range sortedChildren(t.root) {
+		depthFirstPrint(node, "")
+	}
 }
 
 // Search returns whether or not the search string exists in the Trie, and if it does, the associated node.
@@ -73,29 +160,13 @@ func (t *Trie[T]) SearchPrefix(prefix string) map[string]*trieNode[T] {
 	if !found {
 		return keysAndVals
 	}
-	// find all descendants of the node, after trimming the prefix
-	// NOTE: we trim the prefix because getDescendants() would duplicate the first letter of the matched prefix
-	// (it immediately adds the current Node's rune to the prefix, which would duplicate the last rune)
-	trimmedPrefix := string([]rune(prefix)[:len(prefix)-1])
-	return getDescendants[T](node, trimmedPrefix, make(map[string]*trieNode[T]))
-}
-
-// getDescendants is a depth-first search starting at a node and returning a slice of descendant Nodes that represent a valid Key (they have a Value)
-// TODO(dcohen) make this faster (benchmark!) by passing the matchedNodes map by pointer instead of by value
-func getDescendants[T any](currentNode *trieNode[T], prefix string, matchedNodes map[string]*trieNode[T]) map[string]*trieNode[T] {
-	stringUntilNow := fmt.Sprintf("%s%c", prefix, currentNode.Char)
-
-	// Are we a node that contains a value? (end of a Key?)
-	if currentNode.HasValue {
-		matchedNodes[stringUntilNow] = currentNode
-	}
-
-	for _, node := range currentNode.Children {
-		for key, node := range getDescendants[T](node, stringUntilNow, matchedNodes) {
-			matchedNodes[key] = node
+	t.walkNodes(node, newKeyBuffer(prefix), func(key []byte, node *trieNode[T]) WalkAction {
+		if node.HasValue {
+			keysAndVals[string(key)] = node
 		}
-	}
-	return matchedNodes
+		return Continue
+	})
+	return keysAndVals
 }
 
 // This works.
@@ -120,7 +191,138 @@ func depthFirstPrint[T any](currentNode *trieNode[T], acc string) {
 	if currentNode.HasValue {
 		fmt.Printf("Key: %s Value: %v\n", stringUntilNow, currentNode.Value)
 	}
-	for _, node := range currentNode.Children {
+	for _, node := range sortedChildren(currentNode) {
 		depthFirstPrint[T](node, stringUntilNow)
 	}
 }
+
+// sortedChildren returns a node's children ordered by rune.
+// Ordering by rune is the same as ordering the UTF-8 encoded keys bytewise, so walking the trie this way visits keys in lexicographic order.
+func sortedChildren[T any](node *trieNode[T]) []*trieNode[T] {
+	children := make([]*trieNode[T], 0, len(node.Children))
+	for _, child := range node.Children {
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].Char < children[j].Char })
+	return children
+}
+
+// KeyValue is a single key and its value, used wherever results need a stable order
+type KeyValue[T any] struct {
+	Key   string
+	Value T
+}
+
+// Each calls fn for every key in lexicographic order, stopping early if fn returns false
+func (t *Trie[T]) Each(fn func(key string, val T) bool) {
+	for c := t.Seek(""); c.Valid(); c.Next() {
+		if !fn(c.Key(), c.Value()) {
+			return
+		}
+	}
+}
+
+// Range returns all keys k with startKey <= k < endKey, in lexicographic order.
+// An empty endKey means "no upper bound".
+func (t *Trie[T]) Range(startKey, endKey string) []KeyValue[T] {
+	var results []KeyValue[T]
+	for c := t.Seek(startKey); c.Valid(); c.Next() {
+		key := c.Key()
+		if endKey != "" && key >= endKey {
+			break
+		}
+		results = append(results, KeyValue[T]{Key: key, Value: c.Value()})
+	}
+	return results
+}
+
+// Cursor walks the trie's keys in lexicographic order, one key at a time.
+// It keeps a stack of sorted children for the path it's on, so moving to the next key doesn't re-scan from the root.
+// The trie must not be modified while a Cursor is in use.
+type Cursor[T any] struct {
+	// frames[d] holds the sorted children of the node at depth d (the root is depth 0)
+	frames []cursorFrame[T]
+	// runes of the current path, one per frame below the root
+	path []rune
+	// the value-holding node the cursor points at, nil once we've run off the end
+	node *trieNode[T]
+}
+
+type cursorFrame[T any] struct {
+	children []*trieNode[T]
+	next     int
+}
+
+// Seek returns a Cursor positioned at the first key >= key
+func (t *Trie[T]) Seek(key string) *Cursor[T] {
+	c := &Cursor[T]{frames: []cursorFrame[T]{{children: sortedChildren(t.root)}}}
+	currentNode := t.root
+	for _, char := range key {
+		top := &c.frames[len(c.frames)-1]
+		i := sort.Search(len(top.children), func(i int) bool { return top.children[i].Char >= char })
+		if i == len(top.children) || top.children[i].Char != char {
+			// everything from children[i] onwards sorts after key
+			top.next = i
+			c.advance()
+			return c
+		}
+		// follow the key; this node's own value sorts before key, so skip it
+		top.next = i + 1
+		currentNode = top.children[i]
+		c.path = append(c.path, char)
+		c.frames = append(c.frames, cursorFrame[T]{children: sortedChildren(currentNode)})
+	}
+	// we consumed the whole key: it's either a key itself or everything below it sorts after it
+	if currentNode.HasValue {
+		c.node = currentNode
+		return c
+	}
+	c.advance()
+	return c
+}
+
+// advance does a pre-order step through the trie until it finds the next node that holds a value
+func (c *Cursor[T]) advance() {
+	for len(c.frames) > 0 {
+		top := &c.frames[len(c.frames)-1]
+		if top.next < len(top.children) {
+			child := top.children[top.next]
+			top.next++
+			c.path = append(c.path, child.Char)
+			c.frames = append(c.frames, cursorFrame[T]{children: sortedChildren(child)})
+			if child.HasValue {
+				c.node = child
+				return
+			}
+			continue
+		}
+		// this node is exhausted, go back up
+		c.frames = c.frames[:len(c.frames)-1]
+		if len(c.path) > 0 {
+			c.path = c.path[:len(c.path)-1]
+		}
+	}
+	c.node = nil
+}
+
+// Valid reports whether the cursor points at a key (false once it has run off the end)
+func (c *Cursor[T]) Valid() bool {
+	return c.node != nil
+}
+
+// Next moves the cursor to the next key
+func (c *Cursor[T]) Next() {
+	if c.Valid() {
+		c.advance()
+	}
+}
+
+// Key returns the key the cursor points at
+func (c *Cursor[T]) Key() string {
+	return string(c.path)
+}
+
+// Value returns the value for the key the cursor points at
+func (c *Cursor[T]) Value() T {
+	return c.node.Value
+}
@@ -0,0 +1,386 @@
+package prefix_trie
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/groovemonkey/trie-keys-experiment/batch"
+	"github.com/groovemonkey/trie-keys-experiment/stats"
+)
+
+func TestOrderedIteration(t *testing.T) {
+	trie := New[int]()
+	trie.Insert("b", 2)
+	trie.Insert("ab", 1)
+	trie.Insert("c", 3)
+	trie.Insert("a", 0)
+	trie.Insert("héllo", 4)
+	trie.Insert("hello", 5)
+
+	var keys []string
+	trie.Each(func(key string, val int) bool {
+		keys = append(keys, key)
+		return true
+	})
+	expected := []string{"a", "ab", "b", "c", "hello", "héllo"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected keys in order %v, got %v", expected, keys)
+	}
+
+	found, node := trie.Search("héllo")
+	if !found || !node.HasValue || node.Value != 4 {
+		t.Errorf("expected multi-byte keys to hold a value, found=%v node=%v", found, node)
+	}
+}
+
+func TestRangeAndSeek(t *testing.T) {
+	trie := New[int]()
+	for i, key := range []string{"aa", "ab", "abc", "b", "ba", "c"} {
+		trie.Insert(key, i)
+	}
+
+	results := trie.Range("ab", "ba")
+	expected := []KeyValue[int]{{"ab", 1}, {"abc", 2}, {"b", 3}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected Range to return %v, got %v", expected, results)
+	}
+
+	c := trie.Seek("abd")
+	if !c.Valid() || c.Key() != "b" {
+		t.Errorf("expected Seek(abd) to land on b")
+	}
+	c.Next()
+	if !c.Valid() || c.Key() != "ba" || c.Value() != 4 {
+		t.Errorf("expected Next to land on ba")
+	}
+
+	if c := trie.Seek("d"); c.Valid() {
+		t.Errorf("expected Seek past the last key to be invalid, got %s", c.Key())
+	}
+}
+
+func TestOrderStatistics(t *testing.T) {
+	trie := New[int]()
+	keys := []string{"aa", "ab", "abc", "b", "ba", "c"}
+	for i, key := range keys {
+		trie.Insert(key, i)
+	}
+	// re-inserting must not double count
+	trie.Insert("ab", 1)
+
+	if trie.Len() != len(keys) {
+		t.Errorf("expected Len() to be %d, got %d", len(keys), trie.Len())
+	}
+	for i, key := range keys {
+		if rank := trie.Rank(key); rank != i {
+			t.Errorf("expected Rank(%s) to be %d, got %d", key, i, rank)
+		}
+		found, kv := trie.Select(i)
+		if !found || kv.Key != key || kv.Value != i {
+			t.Errorf("expected Select(%d) to be %s, got %v", i, key, kv)
+		}
+	}
+	if found, kv := trie.Select(len(keys)); found {
+		t.Errorf("expected Select past the end to fail, got %v", kv)
+	}
+
+	// "abd" isn't a key: it sits between "abc" and "b"
+	if _, kv := trie.Floor("abd"); kv.Key != "abc" {
+		t.Errorf("expected Floor(abd) to be abc, got %v", kv)
+	}
+	if _, kv := trie.Ceiling("abd"); kv.Key != "b" {
+		t.Errorf("expected Ceiling(abd) to be b, got %v", kv)
+	}
+	if _, kv := trie.Floor("b"); kv.Key != "b" {
+		t.Errorf("expected Floor(b) to be b, got %v", kv)
+	}
+	if _, kv := trie.Next("b"); kv.Key != "ba" {
+		t.Errorf("expected Next(b) to be ba, got %v", kv)
+	}
+	if _, kv := trie.Prev("b"); kv.Key != "abc" {
+		t.Errorf("expected Prev(b) to be abc, got %v", kv)
+	}
+	if found, kv := trie.Prev("aa"); found {
+		t.Errorf("expected nothing before the first key, got %v", kv)
+	}
+}
+
+func TestSearchPrefix(t *testing.T) {
+	trie := New[int]()
+	trie.Insert("héllo", 1)
+	trie.Insert("héllo, world", 2)
+	trie.Insert("hello", 3)
+
+	results := trie.SearchPrefix("hé")
+	if len(results) != 2 || results["héllo"] == nil || results["héllo, world"] == nil {
+		t.Errorf("expected 2 results for a multi-byte prefix, got %v", results)
+	}
+}
+
+func TestSearchPrefixPage(t *testing.T) {
+	trie := New[int]()
+	for i, key := range []string{"a", "b", "ba", "bb", "bc", "bd", "c"} {
+		trie.Insert(key, i)
+	}
+
+	var pages [][]KeyValue[int]
+	opts := PageOptions{Limit: 2}
+	for {
+		page, token := trie.SearchPrefixPage("b", opts)
+		pages = append(pages, page)
+		if token == "" {
+			break
+		}
+		opts.After = token
+	}
+	expected := [][]KeyValue[int]{{{"b", 1}, {"ba", 2}}, {{"bb", 3}, {"bc", 4}}, {{"bd", 5}}}
+	if !reflect.DeepEqual(pages, expected) {
+		t.Errorf("expected pages %v, got %v", expected, pages)
+	}
+
+	var keys []string
+	trie.SearchPrefixFunc("b", func(key string, val int) bool {
+		keys = append(keys, key)
+		return len(keys) < 3
+	})
+	if !reflect.DeepEqual(keys, []string{"b", "ba", "bb"}) {
+		t.Errorf("expected SearchPrefixFunc to stop after 3 keys, got %v", keys)
+	}
+}
+
+func TestReadModifyWrite(t *testing.T) {
+	trie := New[int]()
+
+	if val := Increment(trie, "counter", 2); val != 2 {
+		t.Errorf("expected Increment on a missing key to start from 0, got %d", val)
+	}
+	Increment(trie, "counter", 3)
+
+	val := trie.Update("counter", func(old int, exists bool) int {
+		if !exists {
+			t.Errorf("expected Update to see the existing value")
+		}
+		return old * 10
+	})
+	if val != 50 {
+		t.Errorf("expected Update to return 50, got %d", val)
+	}
+
+	if CompareAndSwap(trie, "counter", 1, 2) {
+		t.Errorf("expected CompareAndSwap with the wrong old value to fail")
+	}
+	if !CompareAndSwap(trie, "counter", 50, 60) {
+		t.Errorf("expected CompareAndSwap with the right old value to succeed")
+	}
+	if CompareAndSwap(trie, "missing", 0, 1) {
+		t.Errorf("expected CompareAndSwap on a missing key to fail")
+	}
+
+	actual, loaded := trie.LoadOrStore("counter", 1)
+	if !loaded || actual != 60 {
+		t.Errorf("expected LoadOrStore to load 60, got %d (loaded=%v)", actual, loaded)
+	}
+	actual, loaded = trie.LoadOrStore("fresh", 1)
+	if loaded || actual != 1 {
+		t.Errorf("expected LoadOrStore to store 1, got %d (loaded=%v)", actual, loaded)
+	}
+
+	if trie.Len() != 2 {
+		t.Errorf("expected 2 keys, got %d", trie.Len())
+	}
+}
+
+func TestDelete(t *testing.T) {
+	trie := New[int]()
+	trie.Insert("ab", 1)
+	trie.Insert("abc", 2)
+	trie.Insert("abd", 3)
+
+	if trie.Delete("a") {
+		t.Errorf("expected deleting a node without a value to fail")
+	}
+	if !trie.Delete("abc") || trie.Delete("abc") {
+		t.Errorf("expected exactly one successful delete")
+	}
+	if trie.Len() != 2 || trie.Rank("abd") != 1 {
+		t.Errorf("expected KeyCounts to be updated, Len()=%d", trie.Len())
+	}
+	if found, _ := trie.Search("abc"); found {
+		t.Errorf("expected the deleted leaf to be pruned")
+	}
+
+	trie.Delete("abd")
+	trie.Delete("ab")
+	if len(trie.root.Children) != 0 {
+		t.Errorf("expected the whole path to be pruned, got %v", trie.root.Children)
+	}
+}
+
+func TestBatchAndTxn(t *testing.T) {
+	trie := New[int]()
+	trie.Insert("net", 3)
+	trie.Insert("taxes", -200)
+
+	var b batch.Batch[int]
+	b.Insert("net", 4)
+	b.Insert("fees", -10)
+	b.Delete("taxes")
+	trie.Apply(&b)
+	if trie.Len() != 2 {
+		t.Errorf("expected the batch to leave 2 keys, got %d", trie.Len())
+	}
+
+	tx := trie.Begin()
+	_, net := tx.Get("net")
+	tx.Insert("net", net+1)
+	if err := tx.Commit(); err != nil {
+		t.Errorf("expected Commit to succeed, got %v", err)
+	}
+	if _, node := trie.Search("net"); node.Value != 5 {
+		t.Errorf("expected the committed value to be 5, got %d", node.Value)
+	}
+
+	// a transaction whose read key changes underneath it must not commit
+	tx = trie.Begin()
+	tx.Get("net")
+	tx.Insert("total", 100)
+	trie.Insert("net", 6)
+	if err := tx.Commit(); err != batch.ErrConflict {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	if found, _ := trie.Search("total"); found {
+		t.Errorf("expected a conflicting transaction to write nothing")
+	}
+}
+
+func TestNewFromSorted(t *testing.T) {
+	kvs := []KeyValue[int]{
+		{"ab", 1},
+		{"abc", 2},
+		{"ad", 3},
+		{"b", 4},
+		{"héllo", 5},
+	}
+	trie, err := NewFromSortedSlice(kvs)
+	if err != nil {
+		t.Fatalf("expected sorted input to load, got %v", err)
+	}
+	if results := trie.Range("", ""); !reflect.DeepEqual(results, kvs) {
+		t.Errorf("expected %v, got %v", kvs, results)
+	}
+	if trie.Len() != len(kvs) || trie.Rank("b") != 3 {
+		t.Errorf("expected KeyCounts to be filled in, Len()=%d", trie.Len())
+	}
+
+	// leaves are loaded without a children map, so make sure they can still grow
+	trie.Insert("abcd", 6)
+	if found, node := trie.Search("abcd"); !found || node.Value != 6 {
+		t.Errorf("expected to insert below a bulk-loaded leaf")
+	}
+
+	_, err = NewFromSortedSlice([]KeyValue[int]{{"b", 1}, {"a", 2}})
+	if !errors.Is(err, ErrUnsorted) {
+		t.Errorf("expected ErrUnsorted for unsorted input, got %v", err)
+	}
+}
+
+func TestArenaTrie(t *testing.T) {
+	trie := NewArena[int]()
+	for i, key := range []string{"profits", "profits.revenue", "profits.revenue.net", "héllo", "hello", ""} {
+		trie.Insert(key, i)
+	}
+	trie.Insert("hello", 10)
+
+	if trie.Len() != 6 {
+		t.Errorf("expected 6 keys, got %d", trie.Len())
+	}
+	for key, expected := range map[string]int{"profits": 0, "profits.revenue.net": 2, "héllo": 3, "hello": 10, "": 5} {
+		if found, val := trie.Search(key); !found || val != expected {
+			t.Errorf("expected %q to be %d, got %v %d", key, expected, found, val)
+		}
+	}
+	if found, _ := trie.Search("prof"); found {
+		t.Errorf("expected a node without a value not to be found")
+	}
+
+	expected := map[string]int{"profits": 0, "profits.revenue": 1, "profits.revenue.net": 2}
+	if results := trie.SearchPrefix("profits"); !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected %v, got %v", expected, results)
+	}
+
+	nodes := trie.NumNodes()
+	if !trie.Delete("profits.revenue.net") || trie.Delete("profits.revenue.net") || trie.Delete("prof") {
+		t.Errorf("expected only the first delete to succeed")
+	}
+	if trie.NumNodes() != nodes-4 {
+		t.Errorf("expected the 4 nodes for \".net\" to be freed, got %d nodes (was %d)", trie.NumNodes(), nodes)
+	}
+	// freed nodes get reused instead of growing the arena
+	size := len(trie.nodes)
+	trie.Insert("profits.revenue.tax", 20)
+	if len(trie.nodes) != size {
+		t.Errorf("expected freed nodes to be reused, arena grew from %d to %d", size, len(trie.nodes))
+	}
+	if found, val := trie.Search("profits.revenue.tax"); !found || val != 20 {
+		t.Errorf("expected the new key to be found, got %v %d", found, val)
+	}
+}
+
+func TestStats(t *testing.T) {
+	trie := New[int]()
+	arena := NewArena[int]()
+	for _, key := range []string{"ab", "abc", "ad"} {
+		trie.Insert(key, 1)
+		arena.Insert(key, 1)
+	}
+	// deleted nodes shouldn't be counted
+	arena.Insert("xyz", 1)
+	arena.Delete("xyz")
+
+	for name, st := range map[string]stats.Stats{"trie": trie.Stats(), "arena": arena.Stats()} {
+		if st.Keys != 3 || st.Nodes != 5 || st.ValuelessNodes != 1 {
+			t.Errorf("%s: expected 3 keys in 5 nodes with 1 valueless one, got %+v", name, st)
+		}
+		if st.MaxDepth != 3 || st.AvgDepth != 7.0/3 {
+			t.Errorf("%s: expected depths of 3 and 7/3, got %d and %v", name, st.MaxDepth, st.AvgDepth)
+		}
+		expected := map[int]int{0: 2, 1: 2, 2: 1}
+		if !reflect.DeepEqual(st.Fanout, expected) {
+			t.Errorf("%s: expected fanout %v, got %v", name, expected, st.Fanout)
+		}
+		if st.NodeBytes <= 0 || st.ChildrenBytes <= 0 || st.ValueBytes <= 0 || st.StringBytes != 0 {
+			t.Errorf("%s: unexpected bytes %+v", name, st)
+		}
+	}
+}
+
+func TestWalkAllocations(t *testing.T) {
+	visitor := func(key []byte, val int, hasValue bool) WalkAction { return Continue }
+	// one node per rune, many of them multi-byte, and the only allocation is the key buffer
+	trie := New[int]()
+	for i := 0; i < 1000; i++ {
+		trie.Insert(fmt.Sprintf("hé_%d.ünits_%d", i%7, i), i)
+	}
+	for _, prefix := range []string{"", "hé_1"} {
+		if allocs := testing.AllocsPerRun(10, func() { trie.Walk(prefix, visitor) }); allocs > 1 {
+			t.Errorf("expected 1 allocation to walk %q, got %v", prefix, allocs)
+		}
+	}
+
+	// keys longer than the buffer's head room grow it, but the bigger buffer is reused for the rest of the walk
+	long := strings.Repeat("ü", 100)
+	var allocs []float64
+	for _, n := range []int{10, 1000} {
+		trie := New[int]()
+		for i := 0; i < n; i++ {
+			trie.Insert(fmt.Sprintf("%s%d", long, i), i)
+		}
+		allocs = append(allocs, testing.AllocsPerRun(10, func() { trie.Walk("", visitor) }))
+	}
+	if allocs[0] != allocs[1] {
+		t.Errorf("expected long keys to take as many allocations with 1000 keys as with 10, got %v", allocs)
+	}
+}
@@ -0,0 +1,50 @@
+package prefix_trie
+
+import (
+	"unsafe"
+
+	"github.com/groovemonkey/trie-keys-experiment/stats"
+)
+
+// Stats walks the whole trie and returns its Stats. Runes are stored in the nodes, so there are no strings.
+func (t *Trie[T]) Stats() stats.Stats {
+	var zero T
+	st := stats.New()
+	var visit func(node *trieNode[T], depth int)
+	visit = func(node *trieNode[T], depth int) {
+		st.AddNode(depth, len(node.Children), node.HasValue)
+		if node.Children != nil {
+			st.ChildrenBytes += stats.MapBytes(len(node.Children), unsafe.Sizeof(rune(0))+unsafe.Sizeof(node))
+		}
+		for _, child := range node.Children {
+			visit(child, depth+1)
+		}
+	}
+	visit(t.root, 0)
+	st.Finish()
+	st.ValueBytes = st.Nodes * int(unsafe.Sizeof(zero))
+	st.NodeBytes = st.Nodes*int(unsafe.Sizeof(trieNode[T]{})) - st.ValueBytes
+	return st
+}
+
+// Stats walks the whole trie and returns its Stats.
+// The bytes count the whole node slice, including free nodes and spare capacity, since that's what's allocated.
+func (t *ArenaTrie[T]) Stats() stats.Stats {
+	var zero T
+	st := stats.New()
+	var visit func(i uint32, depth int)
+	visit = func(i uint32, depth int) {
+		numChildren := 0
+		for c := t.nodes[i].firstChild; c != 0; c = t.nodes[c].nextSibling {
+			numChildren++
+			visit(c, depth+1)
+		}
+		st.AddNode(depth, numChildren, t.nodes[i].HasValue)
+	}
+	visit(0, 0)
+	st.Finish()
+	st.ChildrenBytes = stats.MapBytes(len(t.children), unsafe.Sizeof(arenaEdge{})+unsafe.Sizeof(uint32(0)))
+	st.ValueBytes = cap(t.nodes) * int(unsafe.Sizeof(zero))
+	st.NodeBytes = cap(t.nodes)*int(unsafe.Sizeof(arenaNode[T]{})) - st.ValueBytes
+	return st
+}
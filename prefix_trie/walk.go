@@ -0,0 +1,66 @@
+package prefix_trie
+
+import "unicode/utf8"
+
+// WalkAction tells Walk how to carry on after visiting a node
+type WalkAction int
+
+const (
+	// Continue walks into the node's children
+	Continue WalkAction = iota
+	// SkipChildren carries on with the node's siblings, skipping everything below it
+	SkipChildren
+	// Stop ends the walk
+	Stop
+)
+
+// Visitor is called by Walk for every node under the prefix.
+// hasValue tells apart real keys from nodes that only lead to other keys.
+// key is a reused buffer that's only valid until the visitor returns, so copy it (e.g. string(key)) to keep it.
+type Visitor[T any] func(key []byte, val T, hasValue bool) WalkAction
+
+// Walk does a depth-first walk over the node for prefix and everything below it, without allocating per node.
+// Children are visited in no particular order; use Each or a Cursor for ordered iteration.
+func (t *Trie[T]) Walk(prefix string, visitor Visitor[T]) {
+	found, node := t.Search(prefix)
+	if !found {
+		return
+	}
+	t.walkNodes(node, newKeyBuffer(prefix), func(key []byte, node *trieNode[T]) WalkAction {
+		return visitor(key, node.Value, node.HasValue)
+	})
+}
+
+// newKeyBuffer returns a buffer holding prefix, with some room to grow so walking doesn't reallocate it right away
+func newKeyBuffer(prefix string) []byte {
+	return append(make([]byte, 0, len(prefix)+64), prefix...)
+}
+
+// walkNodes visits node (whose key is key) and then its descendants, depth first
+func (t *Trie[T]) walkNodes(node *trieNode[T], key []byte, fn func(key []byte, node *trieNode[T]) WalkAction) {
+	if fn(key, node) != Continue {
+		return
+	}
+	walkChildren(node, key, fn)
+}
+
+// walkChildren appends each child's rune to the shared key buffer, visits it and recurses.
+// It returns the buffer (which may have grown, so the caller keeps reusing the bigger one),
+// and false once a visitor asked to stop.
+func walkChildren[T any](node *trieNode[T], key []byte, fn func(key []byte, node *trieNode[T]) WalkAction) ([]byte, bool) {
+	keyLen := len(key)
+	for _, child := range node.Children {
+		key = utf8.AppendRune(key[:keyLen], child.Char)
+		switch fn(key, child) {
+		case Stop:
+			return key[:keyLen], false
+		case SkipChildren:
+			continue
+		}
+		var ok bool
+		if key, ok = walkChildren(child, key, fn); !ok {
+			return key[:keyLen], false
+		}
+	}
+	return key[:keyLen], true
+}
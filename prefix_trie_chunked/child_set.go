@@ -0,0 +1,168 @@
+package prefix_trie_chunked
+
+import "sort"
+
+// maxSliceChildren is the most children a node keeps in a sorted slice before switching to a map.
+// A binary search over a handful of chunks is faster than hashing the chunk, and a lot smaller than a map.
+const maxSliceChildren = 8
+
+// childSet holds a node's children, stored according to how many there are:
+// a single child inline, up to maxSliceChildren in a slice sorted by chunk, and any more in a map.
+// Most nodes in a chunked trie have one or two children, so most never allocate anything for them.
+// The zero value is an empty set.
+type childSet[T any] struct {
+	// set when there's exactly one child
+	one *trieNode[T]
+	// set when there are 2..maxSliceChildren children
+	sorted []*trieNode[T]
+	// set when there are more
+	byChunk map[string]*trieNode[T]
+}
+
+// newChildSet returns a set holding children, which must be sorted by chunk
+func newChildSet[T any](children []*trieNode[T]) childSet[T] {
+	switch {
+	case len(children) == 0:
+		return childSet[T]{}
+	case len(children) == 1:
+		return childSet[T]{one: children[0]}
+	case len(children) <= maxSliceChildren:
+		return childSet[T]{sorted: append([]*trieNode[T](nil), children...)}
+	}
+	byChunk := make(map[string]*trieNode[T], len(children))
+	for _, child := range children {
+		byChunk[child.Chunk] = child
+	}
+	return childSet[T]{byChunk: byChunk}
+}
+
+func (c *childSet[T]) len() int {
+	switch {
+	case c.one != nil:
+		return 1
+	case c.byChunk != nil:
+		return len(c.byChunk)
+	}
+	return len(c.sorted)
+}
+
+// search returns where chunk is (or would go) in the sorted slice
+func (c *childSet[T]) search(chunk string) int {
+	return sort.Search(len(c.sorted), func(i int) bool { return c.sorted[i].Chunk >= chunk })
+}
+
+// get returns the child for chunk, or nil
+func (c *childSet[T]) get(chunk string) *trieNode[T] {
+	switch {
+	case c.one != nil:
+		if c.one.Chunk == chunk {
+			return c.one
+		}
+		return nil
+	case c.byChunk != nil:
+		return c.byChunk[chunk]
+	}
+	if i := c.search(chunk); i < len(c.sorted) && c.sorted[i].Chunk == chunk {
+		return c.sorted[i]
+	}
+	return nil
+}
+
+// add adds a child whose chunk isn't in the set yet
+func (c *childSet[T]) add(child *trieNode[T]) {
+	switch {
+	case c.byChunk != nil:
+		c.byChunk[child.Chunk] = child
+	case c.one == nil && len(c.sorted) == 0:
+		c.one = child
+	case c.one != nil:
+		// two children, move to a slice
+		c.sorted = make([]*trieNode[T], 0, 2)
+		if c.one.Chunk < child.Chunk {
+			c.sorted = append(c.sorted, c.one, child)
+		} else {
+			c.sorted = append(c.sorted, child, c.one)
+		}
+		c.one = nil
+	case len(c.sorted) < maxSliceChildren:
+		i := c.search(child.Chunk)
+		c.sorted = append(c.sorted, nil)
+		copy(c.sorted[i+1:], c.sorted[i:])
+		c.sorted[i] = child
+	default:
+		// too many for a slice, move to a map
+		c.byChunk = make(map[string]*trieNode[T], len(c.sorted)+1)
+		for _, sibling := range c.sorted {
+			c.byChunk[sibling.Chunk] = sibling
+		}
+		c.byChunk[child.Chunk] = child
+		c.sorted = nil
+	}
+}
+
+// remove removes the child for chunk, if there is one
+func (c *childSet[T]) remove(chunk string) {
+	switch {
+	case c.one != nil:
+		if c.one.Chunk == chunk {
+			c.one = nil
+		}
+	case c.byChunk != nil:
+		delete(c.byChunk, chunk)
+		// go back to a slice once it's well below the limit, so adding and removing one child around it doesn't flip back and forth
+		if len(c.byChunk) <= maxSliceChildren/2 {
+			*c = newChildSet(c.sortedFromMap())
+		}
+	default:
+		i := c.search(chunk)
+		if i == len(c.sorted) || c.sorted[i].Chunk != chunk {
+			return
+		}
+		c.sorted = append(c.sorted[:i], c.sorted[i+1:]...)
+		if len(c.sorted) == 1 {
+			*c = childSet[T]{one: c.sorted[0]}
+		}
+	}
+}
+
+// each calls fn for every child in no particular order, stopping early if fn returns false.
+// It reports whether it got through all of them.
+func (c *childSet[T]) each(fn func(child *trieNode[T]) bool) bool {
+	switch {
+	case c.one != nil:
+		return fn(c.one)
+	case c.byChunk != nil:
+		for _, child := range c.byChunk {
+			if !fn(child) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, child := range c.sorted {
+		if !fn(child) {
+			return false
+		}
+	}
+	return true
+}
+
+// inOrder returns the children sorted by chunk. The result may be the set's own slice, so don't modify it.
+func (c *childSet[T]) inOrder() []*trieNode[T] {
+	switch {
+	case c.one != nil:
+		return []*trieNode[T]{c.one}
+	case c.byChunk != nil:
+		return c.sortedFromMap()
+	}
+	return c.sorted
+}
+
+func (c *childSet[T]) sortedFromMap() []*trieNode[T] {
+	children := make([]*trieNode[T], 0, len(c.byChunk))
+	for _, child := range c.byChunk {
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].Chunk < children[j].Chunk })
+	return children
+}
@@ -0,0 +1,34 @@
+package prefix_trie_chunked
+
+// ChildEntry describes one direct child of a node, like an entry in a directory listing
+type ChildEntry[T any] struct {
+	Chunk string
+	// the child's value, only meaningful if HasValue is set
+	Value    T
+	HasValue bool
+	// number of keys below the child, not counting the child itself
+	Descendants int
+}
+
+// Children lists the direct child chunks of prefix in order, without fetching the rest of the subtree.
+// An empty prefix lists the top-level chunks. It returns nil if prefix doesn't exist.
+func (t *Trie[T]) Children(prefix string) []ChildEntry[T] {
+	node := t.root
+	if prefix != "" {
+		var found bool
+		if found, node = t.Search(prefix); !found {
+			return nil
+		}
+	}
+
+	children := sortedChildren(node)
+	entries := make([]ChildEntry[T], 0, len(children))
+	for _, child := range children {
+		entry := ChildEntry[T]{Chunk: child.Chunk, Value: child.Value, HasValue: child.HasValue, Descendants: child.KeyCount}
+		if child.HasValue {
+			entry.Descendants--
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
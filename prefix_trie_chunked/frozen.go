@@ -0,0 +1,198 @@
+package prefix_trie_chunked
+
+import (
+	"sort"
+	"strings"
+)
+
+// FrozenTrie is an immutable copy of a Trie, made by Freeze.
+// Instead of a map of pointers per node, all nodes live in one flat slice (in breadth-first order, so every node's
+// children are next to each other and sorted by chunk), and chunks are interned into a single string.
+// The only pointers left are the handful of slice headers, which keeps the GC from having to scan millions of nodes.
+type FrozenTrie[T any] struct {
+	nodes []frozenNode
+	// values of the nodes that have one, see frozenNode.value
+	values []T
+	// every distinct chunk, back to back; chunk i is chunkData[chunkOffsets[i]:chunkOffsets[i+1]]
+	chunkData    string
+	chunkOffsets []uint32
+}
+
+type frozenNode struct {
+	// index of the node's chunk
+	chunk uint32
+	// the node's children are nodes[firstChild : firstChild+numChildren]
+	firstChild  uint32
+	numChildren uint32
+	// number of keys in this node's subtree, including itself
+	keyCount uint32
+	// index into values, only meaningful if hasValue is set
+	value    uint32
+	hasValue bool
+}
+
+// Freeze makes an immutable, compact copy of the trie.
+// Later changes to the trie don't affect the copy.
+func (t *Trie[T]) Freeze() *FrozenTrie[T] {
+	f := &FrozenTrie[T]{}
+	interned := make(map[string]uint32)
+	var chunkData strings.Builder
+	intern := func(chunk string) uint32 {
+		if id, ok := interned[chunk]; ok {
+			return id
+		}
+		id := uint32(len(f.chunkOffsets))
+		interned[chunk] = id
+		f.chunkOffsets = append(f.chunkOffsets, uint32(chunkData.Len()))
+		chunkData.WriteString(chunk)
+		return id
+	}
+
+	// breadth-first, so that queue[i] ends up as nodes[i]
+	queue := []*trieNode[T]{t.root}
+	f.nodes = append(f.nodes, f.freezeNode(t.root, 0))
+	for i := 0; i < len(queue); i++ {
+		children := sortedChildren(queue[i])
+		f.nodes[i].firstChild = uint32(len(queue))
+		f.nodes[i].numChildren = uint32(len(children))
+		for _, child := range children {
+			queue = append(queue, child)
+			f.nodes = append(f.nodes, f.freezeNode(child, intern(child.Chunk)))
+		}
+	}
+	f.chunkOffsets = append(f.chunkOffsets, uint32(chunkData.Len()))
+	f.chunkData = chunkData.String()
+	return f
+}
+
+// freezeNode copies a node's own data (everything but its children)
+func (f *FrozenTrie[T]) freezeNode(node *trieNode[T], chunk uint32) frozenNode {
+	frozen := frozenNode{chunk: chunk, keyCount: uint32(node.KeyCount), hasValue: node.HasValue}
+	if node.HasValue {
+		frozen.value = uint32(len(f.values))
+		f.values = append(f.values, node.Value)
+	}
+	return frozen
+}
+
+func (f *FrozenTrie[T]) chunk(node *frozenNode) string {
+	return f.chunkData[f.chunkOffsets[node.chunk]:f.chunkOffsets[node.chunk+1]]
+}
+
+// child binary searches node's children for chunk
+func (f *FrozenTrie[T]) child(node *frozenNode, chunk string) (uint32, bool) {
+	first := node.firstChild
+	n := int(node.numChildren)
+	i := sort.Search(n, func(i int) bool { return f.chunk(&f.nodes[first+uint32(i)]) >= chunk })
+	if i == n || f.chunk(&f.nodes[first+uint32(i)]) != chunk {
+		return 0, false
+	}
+	return first + uint32(i), true
+}
+
+// find returns the index of the node for key
+func (f *FrozenTrie[T]) find(key string) (uint32, bool) {
+	var current uint32
+	for rest, more := key, true; more; {
+		var chunk string
+		chunk, rest, more = strings.Cut(rest, ".")
+		next, ok := f.child(&f.nodes[current], chunk)
+		if !ok {
+			return 0, false
+		}
+		current = next
+	}
+	return current, true
+}
+
+// Len returns the number of keys in the trie
+func (f *FrozenTrie[T]) Len() int {
+	return int(f.nodes[0].keyCount)
+}
+
+// Search returns the value for key, and whether key exists.
+// Unlike Trie.Search, nodes that are only a prefix of other keys don't count as found.
+func (f *FrozenTrie[T]) Search(key string) (bool, T) {
+	i, ok := f.find(key)
+	if !ok || !f.nodes[i].hasValue {
+		var zero T
+		return false, zero
+	}
+	return true, f.values[f.nodes[i].value]
+}
+
+// SearchPrefix returns all Keys with the given prefix, mapped to their values
+func (f *FrozenTrie[T]) SearchPrefix(prefix string) map[string]T {
+	keysAndVals := make(map[string]T)
+	if len(prefix) == 0 {
+		return keysAndVals
+	}
+	f.Walk(prefix, func(key []byte, val T, hasValue bool) WalkAction {
+		if hasValue {
+			keysAndVals[string(key)] = val
+		}
+		return Continue
+	})
+	return keysAndVals
+}
+
+// Walk does a depth-first walk over the node for prefix and everything below it, see Trie.Walk.
+// Unlike Trie.Walk, children are visited in order.
+func (f *FrozenTrie[T]) Walk(prefix string, visitor Visitor[T]) {
+	if prefix == "" {
+		f.walkChildren(0, newKeyBuffer(""), true, visitor)
+		return
+	}
+	i, ok := f.find(prefix)
+	if !ok {
+		return
+	}
+	node := &f.nodes[i]
+	if visitor([]byte(prefix), f.value(node), node.hasValue) != Continue {
+		return
+	}
+	f.walkChildren(i, newKeyBuffer(prefix), false, visitor)
+}
+
+func (f *FrozenTrie[T]) value(node *frozenNode) T {
+	if !node.hasValue {
+		var zero T
+		return zero
+	}
+	return f.values[node.value]
+}
+
+// walkChildren is walkChildren for frozen nodes
+func (f *FrozenTrie[T]) walkChildren(i uint32, key []byte, isRoot bool, visitor Visitor[T]) ([]byte, bool) {
+	keyLen := len(key)
+	node := &f.nodes[i]
+	for c := node.firstChild; c < node.firstChild+node.numChildren; c++ {
+		child := &f.nodes[c]
+		key = key[:keyLen]
+		if !isRoot {
+			key = append(key, '.')
+		}
+		key = append(key, f.chunk(child)...)
+		switch visitor(key, f.value(child), child.hasValue) {
+		case Stop:
+			return key[:keyLen], false
+		case SkipChildren:
+			continue
+		}
+		var ok bool
+		if key, ok = f.walkChildren(c, key, false, visitor); !ok {
+			return key[:keyLen], false
+		}
+	}
+	return key[:keyLen], true
+}
+
+// AggregateDescendants aggregates the values of all keys with a certain prefix, like mapkeys.Store.AggregateDescendants
+// (so e.g. mapkeys.Sum works as aggFunc). It returns whether there was anything to aggregate, and the result.
+func AggregateDescendants[T Number](f *FrozenTrie[T], prefix string, aggFunc func(keysAndVals map[string]T) T) (bool, float64) {
+	descendants := f.SearchPrefix(prefix)
+	if len(descendants) == 0 {
+		return false, 0
+	}
+	return true, float64(aggFunc(descendants))
+}
@@ -0,0 +1,47 @@
+package prefix_trie_chunked
+
+import "strings"
+
+// interner keeps one copy of every distinct chunk in a trie, so repeated chunks like "revenue" or "sdf" share their
+// bytes instead of each node holding its own. It counts the nodes using each chunk, so chunks that no node uses
+// anymore are dropped.
+type interner struct {
+	chunks map[string]internedChunk
+}
+
+type internedChunk struct {
+	s    string
+	refs int
+}
+
+// NewInterned returns an empty trie that interns its chunks.
+// This saves memory when the same chunks show up all over the trie (metric names, deep keys with repeated segments),
+// at the cost of a map lookup for every node that gets created or pruned.
+func NewInterned[T any]() *Trie[T] {
+	t := New[T]()
+	t.chunks = &interner{chunks: make(map[string]internedChunk)}
+	return t
+}
+
+// intern returns the shared copy of chunk, making one if this is the first node to use it
+func (in *interner) intern(chunk string) string {
+	interned, ok := in.chunks[chunk]
+	if !ok {
+		// chunk is usually a slice of the whole key, so copy it to avoid keeping the key alive
+		interned.s = strings.Clone(chunk)
+	}
+	interned.refs++
+	in.chunks[interned.s] = interned
+	return interned.s
+}
+
+// release is called when a node using chunk is pruned
+func (in *interner) release(chunk string) {
+	interned := in.chunks[chunk]
+	if interned.refs <= 1 {
+		delete(in.chunks, chunk)
+		return
+	}
+	interned.refs--
+	in.chunks[chunk] = interned
+}
@@ -0,0 +1,95 @@
+package prefix_trie_chunked
+
+import "strings"
+
+// Order statistics: every node keeps a KeyCount of the keys in its subtree, so we can find
+// the kth key or a key's position by walking a single path instead of scanning from the start.
+// "Order" here is chunk-by-chunk order, see CompareKeys.
+
+// Len returns the number of keys in the trie
+func (t *Trie[T]) Len() int {
+	return t.root.KeyCount
+}
+
+// hasKey reports whether key was inserted (Search also matches nodes that are only a prefix of other keys)
+func (t *Trie[T]) hasKey(key string) bool {
+	found, node := t.Search(key)
+	return found && node.HasValue
+}
+
+// Rank returns the number of keys that sort before key. key doesn't have to exist.
+func (t *Trie[T]) Rank(key string) int {
+	rank := 0
+	currentNode := t.root
+	for _, chunk := range strings.Split(key, ".") {
+		// this node's key is a proper prefix of key, so it sorts first
+		if currentNode.HasValue {
+			rank++
+		}
+		// so do all subtrees hanging off a smaller chunk
+		currentNode.children.each(func(child *trieNode[T]) bool {
+			if child.Chunk < chunk {
+				rank += child.KeyCount
+			}
+			return true
+		})
+		child := currentNode.children.get(chunk)
+		if child == nil {
+			return rank
+		}
+		currentNode = child
+	}
+	return rank
+}
+
+// Select returns the kth key in order (counting from 0), and false if there are k or fewer keys
+func (t *Trie[T]) Select(k int) (bool, KeyValue[T]) {
+	if k < 0 || k >= t.root.KeyCount {
+		return false, KeyValue[T]{}
+	}
+	var path []string
+	currentNode := t.root
+	for {
+		if currentNode.HasValue {
+			if k == 0 {
+				return true, KeyValue[T]{Key: strings.Join(path, "."), Value: currentNode.Value}
+			}
+			k--
+		}
+		for _, child := range sortedChildren(currentNode) {
+			if k < child.KeyCount {
+				currentNode = child
+				path = append(path, child.Chunk)
+				break
+			}
+			k -= child.KeyCount
+		}
+	}
+}
+
+// Ceiling returns the smallest key >= key
+func (t *Trie[T]) Ceiling(key string) (bool, KeyValue[T]) {
+	return t.Select(t.Rank(key))
+}
+
+// Floor returns the largest key <= key
+func (t *Trie[T]) Floor(key string) (bool, KeyValue[T]) {
+	if t.hasKey(key) {
+		return t.Select(t.Rank(key))
+	}
+	return t.Prev(key)
+}
+
+// Next returns the smallest key > key
+func (t *Trie[T]) Next(key string) (bool, KeyValue[T]) {
+	rank := t.Rank(key)
+	if t.hasKey(key) {
+		rank++
+	}
+	return t.Select(rank)
+}
+
+// Prev returns the largest key < key
+func (t *Trie[T]) Prev(key string) (bool, KeyValue[T]) {
+	return t.Select(t.Rank(key) - 1)
+}
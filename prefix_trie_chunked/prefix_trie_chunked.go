@@ -2,6 +2,7 @@ package prefix_trie_chunked
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -10,45 +11,132 @@ type trieNode[T any] struct {
 	Value T
 	// avoid mistaking initialized zero values for intentional zero values
 	HasValue bool
-	Children map[string]*trieNode[T]
+	// number of keys stored in this node's subtree, including this node itself
+	KeyCount int
+	// stamped from Trie.version on every write to this node's value, so transactions can tell whether a key changed
+	version  uint64
+	children childSet[T]
 }
 
 type Trie[T any] struct {
 	root *trieNode[T]
+	// bumped on every write
+	version uint64
+	// shares storage between identical chunks, nil unless the trie was made with NewInterned
+	chunks *interner
 }
 
 func New[T any]() *Trie[T] {
-	return &Trie[T]{root: &trieNode[T]{Children: make(map[string]*trieNode[T])}}
+	return &Trie[T]{root: &trieNode[T]{}}
 }
 
 func (t *Trie[T]) Insert(s string, val T) {
+	// collect the path on the stack so KeyCounts can be fixed up without walking the key twice
+	var pathBuf [32]*trieNode[T]
+	node, path := t.findOrCreate(s, pathBuf[:0])
+	t.setValue(path, node, val)
+}
+
+// findOrCreate walks to the node for key, creating any missing nodes on the way.
+// It appends every node on the path to path, from the root down to the key's node.
+func (t *Trie[T]) findOrCreate(key string, path []*trieNode[T]) (*trieNode[T], []*trieNode[T]) {
 	currentNode := t.root
+	path = append(path, currentNode)
 	// break the key string into dot-separated chunks
-	chunked := strings.Split(s, ".")
-
-	for i, chunk := range chunked {
-		child, ok := currentNode.Children[chunk]
+	for _, chunk := range strings.Split(key, ".") {
+		child := currentNode.children.get(chunk)
 		// If there's no such child, create one
-		if !ok {
-			child = &trieNode[T]{Chunk: chunk, Children: make(map[string]*trieNode[T])}
-			currentNode.Children[chunk] = child
-		}
-		// is this a leaf node? If so, set the value.
-		if i == (len(s) - 1) {
-			child.Value = val
-			child.HasValue = true
+		if child == nil {
+			if t.chunks != nil {
+				chunk = t.chunks.intern(chunk)
+			}
+			child = &trieNode[T]{Chunk: chunk}
+			currentNode.children.add(child)
 		}
 		// set currentNode for the next iteration
 		currentNode = child
+		path = append(path, currentNode)
+	}
+	return currentNode, path
+}
+
+// findPath appends every node on the path to key to path, from the root down to the key's node.
+// It returns nil if there's no node for key.
+func (t *Trie[T]) findPath(key string, path []*trieNode[T]) []*trieNode[T] {
+	currentNode := t.root
+	path = append(path, currentNode)
+	for _, chunk := range strings.Split(key, ".") {
+		child := currentNode.children.get(chunk)
+		if child == nil {
+			return nil
+		}
+		currentNode = child
+		path = append(path, currentNode)
+	}
+	return path
+}
+
+// setValue sets the value of the node at the end of path, counting it as a new key on the way down if it didn't have one
+func (t *Trie[T]) setValue(path []*trieNode[T], node *trieNode[T], val T) {
+	if !node.HasValue {
+		for _, pathNode := range path {
+			pathNode.KeyCount++
+		}
+	}
+	node.Value = val
+	node.HasValue = true
+	t.touch(node)
+}
+
+// touch records a write to node's value
+func (t *Trie[T]) touch(node *trieNode[T]) {
+	t.version++
+	node.version = t.version
+}
+
+// Delete removes key from the trie and reports whether it was there.
+// Nodes that no longer lead to any key are pruned.
+func (t *Trie[T]) Delete(key string) bool {
+	var pathBuf [32]*trieNode[T]
+	path := t.findPath(key, pathBuf[:0])
+	if path == nil {
+		return false
+	}
+	node := path[len(path)-1]
+	if !node.HasValue {
+		return false
+	}
+	var zero T
+	node.Value = zero
+	node.HasValue = false
+	t.touch(node)
+	for _, pathNode := range path {
+		pathNode.KeyCount--
+	}
+	// cut off the highest node on the path that no longer has any keys below it (never the root)
+	for i := 1; i < len(path); i++ {
+		if path[i].KeyCount == 0 {
+			path[i-1].children.remove(path[i].Chunk)
+			// nothing below path[i] holds a key, so the rest of the path is all that's being pruned
+			if t.chunks != nil {
+				for _, pruned := range path[i:] {
+					t.chunks.release(pruned.Chunk)
+				}
+			}
+			break
+		}
 	}
+	return true
 }
 
 func (t *Trie[T]) DepthFirstPrint() {
 	if t.root == nil {
 		return
 	}
-	currentNode := t.root
-	depthFirstPrint(currentNode, "")
+	// the root node doesn't hold a chunk, so start with its children
+	for _, node := range sortedChildren(t.root) {
+		depthFirstPrint(node, node.Chunk)
+	}
 }
 
 // Search returns whether or not the search string exists in the Trie, and if it does, the associated node.
@@ -59,8 +147,8 @@ func (t *Trie[T]) Search(s string) (bool, *trieNode[T]) {
 
 	currentNode := t.root
 	for _, chunk := range chunked {
-		child, ok := currentNode.Children[chunk]
-		if !ok {
+		child := currentNode.children.get(chunk)
+		if child == nil {
 			return false, returnVal
 		}
 		currentNode = child
@@ -81,29 +169,13 @@ func (t *Trie[T]) SearchPrefix(prefix string) map[string]*trieNode[T] {
 	if !found {
 		return keysAndVals
 	}
-	// find all descendants of the node, after trimming the prefix
-	// NOTE: we trim the prefix because getDescendants() would duplicate the first letter of the matched prefix
-	// (it immediately adds the current Node's rune to the prefix, which would duplicate the last rune)
-	trimmedPrefix := string([]rune(prefix)[:len(prefix)-1])
-	return getDescendants[T](node, trimmedPrefix, make(map[string]*trieNode[T]))
-}
-
-// getDescendants is a depth-first search starting at a node and returning a slice of descendant Nodes that represent a valid Key (they have a Value)
-// TODO(dcohen) make this faster (benchmark!) by passing the matchedNodes map by pointer instead of by value
-func getDescendants[T any](currentNode *trieNode[T], prefix string, matchedNodes map[string]*trieNode[T]) map[string]*trieNode[T] {
-	stringUntilNow := fmt.Sprintf("%s.%s", prefix, currentNode.Chunk)
-
-	// Are we a node that contains a value? (end of a Key?)
-	if currentNode.HasValue {
-		matchedNodes[stringUntilNow] = currentNode
-	}
-
-	for _, node := range currentNode.Children {
-		for key, node := range getDescendants[T](node, stringUntilNow, matchedNodes) {
-			matchedNodes[key] = node
+	t.walkNodes(node, newKeyBuffer(prefix), func(key []byte, node *trieNode[T]) WalkAction {
+		if node.HasValue {
+			keysAndVals[string(key)] = node
 		}
-	}
-	return matchedNodes
+		return Continue
+	})
+	return keysAndVals
 }
 
 // This works.
@@ -122,13 +194,160 @@ func getDescendants[T any](currentNode *trieNode[T], prefix string, matchedNodes
 // depthFirstPrint with accumulator
 // TODO(dcohen) return a string here, by doing the normal recursive "return acc + depthFirstPrint(...)"
 func depthFirstPrint[T any](currentNode *trieNode[T], acc string) {
-	stringUntilNow := fmt.Sprintf("%s.%s", acc, currentNode.Chunk)
+	// Is this the last chunk of a Key?
+	if currentNode.HasValue {
+		fmt.Printf("Key: %s Value: %v\n", acc, currentNode.Value)
+	}
+	for _, node := range sortedChildren(currentNode) {
+		depthFirstPrint[T](node, fmt.Sprintf("%s.%s", acc, node.Chunk))
+	}
+}
 
-	// Is this the last rune of a Key?
+// sortedChildren returns a node's children ordered by chunk
+func sortedChildren[T any](node *trieNode[T]) []*trieNode[T] {
+	return node.children.inOrder()
+}
+
+// CompareKeys orders keys the way the trie stores them: chunk by chunk. Use it to sort input for NewFromSorted.
+// This isn't quite plain string order, e.g. "a.b" sorts before "a-b" here even though '-' < '.',
+// because the chunk "a" sorts before the chunk "a-b".
+func CompareKeys(a, b string) int {
+	for {
+		aChunk, aRest, aMore := strings.Cut(a, ".")
+		bChunk, bRest, bMore := strings.Cut(b, ".")
+		if c := strings.Compare(aChunk, bChunk); c != 0 {
+			return c
+		}
+		switch {
+		case aMore && bMore:
+			a, b = aRest, bRest
+		case aMore:
+			return 1
+		case bMore:
+			return -1
+		default:
+			return 0
+		}
+	}
+}
+
+// KeyValue is a single key and its value, used wherever results need a stable order
+type KeyValue[T any] struct {
+	Key   string
+	Value T
+}
+
+// Each calls fn for every key in order, stopping early if fn returns false.
+// Keys are ordered chunk by chunk (see CompareKeys).
+func (t *Trie[T]) Each(fn func(key string, val T) bool) {
+	for c := t.Seek(""); c.Valid(); c.Next() {
+		if !fn(c.Key(), c.Value()) {
+			return
+		}
+	}
+}
+
+// Range returns all keys k with startKey <= k < endKey, ordered chunk by chunk (see CompareKeys).
+// An empty endKey means "no upper bound".
+func (t *Trie[T]) Range(startKey, endKey string) []KeyValue[T] {
+	var results []KeyValue[T]
+	for c := t.Seek(startKey); c.Valid(); c.Next() {
+		key := c.Key()
+		if endKey != "" && CompareKeys(key, endKey) >= 0 {
+			break
+		}
+		results = append(results, KeyValue[T]{Key: key, Value: c.Value()})
+	}
+	return results
+}
+
+// Cursor walks the trie's keys in order, one key at a time.
+// It keeps a stack of sorted children for the path it's on, so moving to the next key doesn't re-scan from the root.
+// The trie must not be modified while a Cursor is in use.
+type Cursor[T any] struct {
+	// frames[d] holds the sorted children of the node at depth d (the root is depth 0)
+	frames []cursorFrame[T]
+	// chunks of the current path, one per frame below the root
+	path []string
+	// the value-holding node the cursor points at, nil once we've run off the end
+	node *trieNode[T]
+}
+
+type cursorFrame[T any] struct {
+	children []*trieNode[T]
+	next     int
+}
+
+// Seek returns a Cursor positioned at the first key >= key
+func (t *Trie[T]) Seek(key string) *Cursor[T] {
+	c := &Cursor[T]{frames: []cursorFrame[T]{{children: sortedChildren(t.root)}}}
+	currentNode := t.root
+	for _, chunk := range strings.Split(key, ".") {
+		top := &c.frames[len(c.frames)-1]
+		i := sort.Search(len(top.children), func(i int) bool { return top.children[i].Chunk >= chunk })
+		if i == len(top.children) || top.children[i].Chunk != chunk {
+			// everything from children[i] onwards sorts after key
+			top.next = i
+			c.advance()
+			return c
+		}
+		// follow the key; this node's own value sorts before key, so skip it
+		top.next = i + 1
+		currentNode = top.children[i]
+		c.path = append(c.path, chunk)
+		c.frames = append(c.frames, cursorFrame[T]{children: sortedChildren(currentNode)})
+	}
+	// we consumed the whole key: it's either a key itself or everything below it sorts after it
 	if currentNode.HasValue {
-		fmt.Printf("Key: %s Value: %v\n", stringUntilNow, currentNode.Value)
+		c.node = currentNode
+		return c
+	}
+	c.advance()
+	return c
+}
+
+// advance does a pre-order step through the trie until it finds the next node that holds a value
+func (c *Cursor[T]) advance() {
+	for len(c.frames) > 0 {
+		top := &c.frames[len(c.frames)-1]
+		if top.next < len(top.children) {
+			child := top.children[top.next]
+			top.next++
+			c.path = append(c.path, child.Chunk)
+			c.frames = append(c.frames, cursorFrame[T]{children: sortedChildren(child)})
+			if child.HasValue {
+				c.node = child
+				return
+			}
+			continue
+		}
+		// this node is exhausted, go back up
+		c.frames = c.frames[:len(c.frames)-1]
+		if len(c.path) > 0 {
+			c.path = c.path[:len(c.path)-1]
+		}
 	}
-	for _, node := range currentNode.Children {
-		depthFirstPrint[T](node, stringUntilNow)
+	c.node = nil
+}
+
+// Valid reports whether the cursor points at a key (false once it has run off the end)
+func (c *Cursor[T]) Valid() bool {
+	return c.node != nil
+}
+
+// Next moves the cursor to the next key
+func (c *Cursor[T]) Next() {
+	if c.Valid() {
+		c.advance()
 	}
 }
+
+// Key returns the key the cursor points at
+func (c *Cursor[T]) Key() string {
+	return strings.Join(c.path, ".")
+}
+
+// Value returns the value for the key the cursor points at
+func (c *Cursor[T]) Value() T {
+	return c.node.Value
+}
@@ -0,0 +1,594 @@
+package prefix_trie_chunked
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/groovemonkey/trie-keys-experiment/batch"
+	"github.com/groovemonkey/trie-keys-experiment/stats"
+)
+
+func TestOrderedIteration(t *testing.T) {
+	trie := New[int]()
+	trie.Insert("profits.revenue.net", 3)
+	trie.Insert("profits.revenue", 0)
+	trie.Insert("business_summary.departments.IT", 12)
+	trie.Insert("profits.revenue.basket", 30)
+	trie.Insert("a-b", 1)
+	trie.Insert("a.b", 2)
+
+	var keys []string
+	trie.Each(func(key string, val int) bool {
+		keys = append(keys, key)
+		return true
+	})
+	expected := []string{
+		"a.b",
+		"a-b",
+		"business_summary.departments.IT",
+		"profits.revenue",
+		"profits.revenue.basket",
+		"profits.revenue.net",
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected keys in order %v, got %v", expected, keys)
+	}
+
+	found, node := trie.Search("profits.revenue.net")
+	if !found || !node.HasValue || node.Value != 3 {
+		t.Errorf("expected profits.revenue.net to hold a value, found=%v node=%v", found, node)
+	}
+}
+
+func TestRangeAndSeek(t *testing.T) {
+	trie := New[int]()
+	for i, key := range []string{"a.a", "a.b", "a.b.c", "b", "b.a", "c"} {
+		trie.Insert(key, i)
+	}
+
+	results := trie.Range("a.b", "b.a")
+	expected := []KeyValue[int]{{"a.b", 1}, {"a.b.c", 2}, {"b", 3}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected Range to return %v, got %v", expected, results)
+	}
+
+	c := trie.Seek("a.b.d")
+	if !c.Valid() || c.Key() != "b" {
+		t.Errorf("expected Seek(a.b.d) to land on b")
+	}
+	c.Next()
+	if !c.Valid() || c.Key() != "b.a" || c.Value() != 4 {
+		t.Errorf("expected Next to land on b.a")
+	}
+
+	if c := trie.Seek("d"); c.Valid() {
+		t.Errorf("expected Seek past the last key to be invalid, got %s", c.Key())
+	}
+}
+
+func TestOrderStatistics(t *testing.T) {
+	trie := New[int]()
+	keys := []string{"a.a", "a.b", "a.b.c", "b", "b.a", "c"}
+	for i, key := range keys {
+		trie.Insert(key, i)
+	}
+	// re-inserting must not double count
+	trie.Insert("a.b", 1)
+
+	if trie.Len() != len(keys) {
+		t.Errorf("expected Len() to be %d, got %d", len(keys), trie.Len())
+	}
+	for i, key := range keys {
+		if rank := trie.Rank(key); rank != i {
+			t.Errorf("expected Rank(%s) to be %d, got %d", key, i, rank)
+		}
+		found, kv := trie.Select(i)
+		if !found || kv.Key != key || kv.Value != i {
+			t.Errorf("expected Select(%d) to be %s, got %v", i, key, kv)
+		}
+	}
+	if found, kv := trie.Select(len(keys)); found {
+		t.Errorf("expected Select past the end to fail, got %v", kv)
+	}
+
+	// "a.b.d" isn't a key: it sits between "a.b.c" and "b"
+	if _, kv := trie.Floor("a.b.d"); kv.Key != "a.b.c" {
+		t.Errorf("expected Floor(a.b.d) to be a.b.c, got %v", kv)
+	}
+	if _, kv := trie.Ceiling("a.b.d"); kv.Key != "b" {
+		t.Errorf("expected Ceiling(a.b.d) to be b, got %v", kv)
+	}
+	if _, kv := trie.Next("b"); kv.Key != "b.a" {
+		t.Errorf("expected Next(b) to be b.a, got %v", kv)
+	}
+	if _, kv := trie.Prev("b"); kv.Key != "a.b.c" {
+		t.Errorf("expected Prev(b) to be a.b.c, got %v", kv)
+	}
+	if found, kv := trie.Prev("a.a"); found {
+		t.Errorf("expected nothing before the first key, got %v", kv)
+	}
+}
+
+func TestSearchPrefix(t *testing.T) {
+	trie := New[int]()
+	trie.Insert("business_summary.departments.finance", 0)
+	trie.Insert("business_summary.departments.software", 100)
+	trie.Insert("business_summary.revenue.top_line", 70)
+	trie.Insert("business_summary.revenue", 50)
+	trie.Insert("business_summary.revenues", 1)
+
+	results := trie.SearchPrefix("business_summary.revenue")
+	if len(results) != 2 || results["business_summary.revenue"] == nil || results["business_summary.revenue.top_line"] == nil {
+		t.Errorf("expected 2 results with full keys, got %v", results)
+	}
+}
+
+func TestSearchPrefixPage(t *testing.T) {
+	trie := New[int]()
+	for i, key := range []string{"a", "b", "b.a", "b.b", "b.c", "b.d", "bb", "c"} {
+		trie.Insert(key, i)
+	}
+
+	var pages [][]KeyValue[int]
+	opts := PageOptions{Limit: 2}
+	for {
+		page, token := trie.SearchPrefixPage("b", opts)
+		pages = append(pages, page)
+		if token == "" {
+			break
+		}
+		opts.After = token
+	}
+	expected := [][]KeyValue[int]{{{"b", 1}, {"b.a", 2}}, {{"b.b", 3}, {"b.c", 4}}, {{"b.d", 5}}}
+	if !reflect.DeepEqual(pages, expected) {
+		t.Errorf("expected pages %v, got %v", expected, pages)
+	}
+
+	page, token := trie.SearchPrefixPage("b", PageOptions{Offset: 3, Limit: 1})
+	if len(page) != 1 || page[0].Key != "b.c" || token != "b.c" {
+		t.Errorf("expected offset 3 to return b.c, got %v (token %s)", page, token)
+	}
+
+	var keys []string
+	trie.SearchPrefixFunc("b", func(key string, val int) bool {
+		keys = append(keys, key)
+		return len(keys) < 3
+	})
+	if !reflect.DeepEqual(keys, []string{"b", "b.a", "b.b"}) {
+		t.Errorf("expected SearchPrefixFunc to stop after 3 keys, got %v", keys)
+	}
+}
+
+func TestChildren(t *testing.T) {
+	trie := New[int]()
+	trie.Insert("business_summary.departments.finance", 0)
+	trie.Insert("business_summary.departments.software", 100)
+	trie.Insert("business_summary.revenue", 50)
+	trie.Insert("business_summary.revenue.top_line", 70)
+	trie.Insert("profits", 3)
+
+	top := trie.Children("")
+	expected := []ChildEntry[int]{
+		{Chunk: "business_summary", Descendants: 4},
+		{Chunk: "profits", Value: 3, HasValue: true},
+	}
+	if !reflect.DeepEqual(top, expected) {
+		t.Errorf("expected top-level children %v, got %v", expected, top)
+	}
+
+	children := trie.Children("business_summary")
+	expected = []ChildEntry[int]{
+		{Chunk: "departments", Descendants: 2},
+		{Chunk: "revenue", Value: 50, HasValue: true, Descendants: 1},
+	}
+	if !reflect.DeepEqual(children, expected) {
+		t.Errorf("expected children %v, got %v", expected, children)
+	}
+
+	if children := trie.Children("nope"); children != nil {
+		t.Errorf("expected no children for a missing prefix, got %v", children)
+	}
+}
+
+func TestReadModifyWrite(t *testing.T) {
+	trie := New[int]()
+
+	if val := Increment(trie, "metrics.counter", 2); val != 2 {
+		t.Errorf("expected Increment on a missing key to start from 0, got %d", val)
+	}
+	Increment(trie, "metrics.counter", 3)
+
+	val := trie.Update("metrics.counter", func(old int, exists bool) int {
+		if !exists {
+			t.Errorf("expected Update to see the existing value")
+		}
+		return old * 10
+	})
+	if val != 50 {
+		t.Errorf("expected Update to return 50, got %d", val)
+	}
+
+	if CompareAndSwap(trie, "metrics.counter", 1, 2) {
+		t.Errorf("expected CompareAndSwap with the wrong old value to fail")
+	}
+	if !CompareAndSwap(trie, "metrics.counter", 50, 60) {
+		t.Errorf("expected CompareAndSwap with the right old value to succeed")
+	}
+	if CompareAndSwap(trie, "missing", 0, 1) {
+		t.Errorf("expected CompareAndSwap on a missing key to fail")
+	}
+
+	actual, loaded := trie.LoadOrStore("metrics.counter", 1)
+	if !loaded || actual != 60 {
+		t.Errorf("expected LoadOrStore to load 60, got %d (loaded=%v)", actual, loaded)
+	}
+	actual, loaded = trie.LoadOrStore("fresh", 1)
+	if loaded || actual != 1 {
+		t.Errorf("expected LoadOrStore to store 1, got %d (loaded=%v)", actual, loaded)
+	}
+
+	if trie.Len() != 2 {
+		t.Errorf("expected 2 keys, got %d", trie.Len())
+	}
+}
+
+func TestDelete(t *testing.T) {
+	trie := New[int]()
+	trie.Insert("profits.revenue", 1)
+	trie.Insert("profits.revenue.net", 2)
+	trie.Insert("profits.revenue.taxes", 3)
+
+	if trie.Delete("profits") {
+		t.Errorf("expected deleting a node without a value to fail")
+	}
+	if !trie.Delete("profits.revenue.net") || trie.Delete("profits.revenue.net") {
+		t.Errorf("expected exactly one successful delete")
+	}
+	if trie.Len() != 2 || trie.Rank("profits.revenue.taxes") != 1 {
+		t.Errorf("expected KeyCounts to be updated, Len()=%d", trie.Len())
+	}
+	if found, _ := trie.Search("profits.revenue.net"); found {
+		t.Errorf("expected the deleted leaf to be pruned")
+	}
+
+	trie.Delete("profits.revenue.taxes")
+	trie.Delete("profits.revenue")
+	if trie.root.children.len() != 0 {
+		t.Errorf("expected the whole path to be pruned, got %v", trie.root.children.inOrder())
+	}
+}
+
+func TestBatchAndTxn(t *testing.T) {
+	trie := New[int]()
+	trie.Insert("profits.revenue.net", 3)
+	trie.Insert("profits.revenue.taxes", -200)
+
+	var b batch.Batch[int]
+	b.Insert("profits.revenue.net", 4)
+	b.Insert("profits.revenue.fees", -10)
+	b.Delete("profits.revenue.taxes")
+	trie.Apply(&b)
+	if trie.Len() != 2 {
+		t.Errorf("expected the batch to leave 2 keys, got %d", trie.Len())
+	}
+
+	tx := trie.Begin()
+	_, net := tx.Get("profits.revenue.net")
+	tx.Insert("profits.revenue.net", net+1)
+	if _, val := tx.Get("profits.revenue.net"); val != 5 {
+		t.Errorf("expected the transaction to read its own write, got %d", val)
+	}
+	if found, node := trie.Search("profits.revenue.net"); !found || node.Value != 4 {
+		t.Errorf("expected the write to stay buffered until Commit")
+	}
+	if err := tx.Commit(); err != nil {
+		t.Errorf("expected Commit to succeed, got %v", err)
+	}
+
+	// a transaction whose read key changes underneath it must not commit
+	tx = trie.Begin()
+	tx.Get("profits.revenue.net")
+	tx.Get("profits.revenue.missing")
+	tx.Insert("profits.revenue.total", 100)
+	trie.Insert("profits.revenue.missing", 1)
+	if err := tx.Commit(); err != batch.ErrConflict {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	if found, _ := trie.Search("profits.revenue.total"); found {
+		t.Errorf("expected a conflicting transaction to write nothing")
+	}
+}
+
+func TestNewFromSorted(t *testing.T) {
+	kvs := []KeyValue[int]{
+		{"a.b", 1},
+		{"a.b.c", 2},
+		{"a.d", 3},
+		{"a-b", 4},
+		{"b", 5},
+	}
+	trie, err := NewFromSortedSlice(kvs)
+	if err != nil {
+		t.Fatalf("expected sorted input to load, got %v", err)
+	}
+	if results := trie.Range("", ""); !reflect.DeepEqual(results, kvs) {
+		t.Errorf("expected %v, got %v", kvs, results)
+	}
+	if trie.Len() != len(kvs) || trie.Rank("a-b") != 3 {
+		t.Errorf("expected KeyCounts to be filled in, Len()=%d", trie.Len())
+	}
+
+	// bulk-loaded leaves have no children, so make sure they can still grow
+	trie.Insert("a.b.c.d", 6)
+	if found, node := trie.Search("a.b.c.d"); !found || node.Value != 6 {
+		t.Errorf("expected to insert below a bulk-loaded leaf")
+	}
+
+	_, err = NewFromSortedSlice([]KeyValue[int]{{"b", 1}, {"a", 2}})
+	if !errors.Is(err, ErrUnsorted) {
+		t.Errorf("expected ErrUnsorted for unsorted input, got %v", err)
+	}
+	_, err = NewFromSortedSlice([]KeyValue[int]{{"a", 1}, {"a", 2}})
+	if !errors.Is(err, ErrUnsorted) {
+		t.Errorf("expected ErrUnsorted for duplicate keys, got %v", err)
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	trie := New[int]()
+	trie.Insert("profits.revenue", 1)
+	trie.Insert("profits.revenue.net", 2)
+	trie.Insert("profits.revenue.taxes", -3)
+	trie.Insert("profits.costs.revenue", 4)
+	trie.Insert("foo", 5)
+
+	frozen := trie.Freeze()
+	// the frozen copy must not see later changes
+	trie.Insert("profits.revenue.fees", 100)
+
+	if frozen.Len() != 5 {
+		t.Errorf("expected 5 keys, got %d", frozen.Len())
+	}
+	if found, val := frozen.Search("profits.revenue.net"); !found || val != 2 {
+		t.Errorf("expected to find profits.revenue.net=2, got %v %d", found, val)
+	}
+	if found, _ := frozen.Search("profits"); found {
+		t.Errorf("expected a node without a value not to be found")
+	}
+	if found, _ := frozen.Search("profits.revenue.fees"); found {
+		t.Errorf("expected keys inserted after Freeze to be missing")
+	}
+
+	results := frozen.SearchPrefix("profits.revenue")
+	expected := map[string]int{"profits.revenue": 1, "profits.revenue.net": 2, "profits.revenue.taxes": -3}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected %v, got %v", expected, results)
+	}
+
+	valid, sum := AggregateDescendants(frozen, "profits", func(keysAndVals map[string]int) int {
+		total := 0
+		for _, val := range keysAndVals {
+			total += val
+		}
+		return total
+	})
+	if !valid || sum != 4 {
+		t.Errorf("expected sum of 4 under profits, got %v", sum)
+	}
+
+	var keys []string
+	frozen.Walk("", func(key []byte, val int, hasValue bool) WalkAction {
+		if hasValue {
+			keys = append(keys, string(key))
+		}
+		return Continue
+	})
+	if !reflect.DeepEqual(keys, []string{"foo", "profits.costs.revenue", "profits.revenue", "profits.revenue.net", "profits.revenue.taxes"}) {
+		t.Errorf("expected Walk to visit keys in order, got %v", keys)
+	}
+}
+
+func TestArenaTrie(t *testing.T) {
+	trie := NewArena[int]()
+	for i, key := range []string{"profits", "profits.revenue", "profits.revenue.net", "losses.revenue.net", "a-b", ""} {
+		trie.Insert(key, i)
+	}
+	trie.Insert("a-b", 10)
+
+	if trie.Len() != 6 {
+		t.Errorf("expected 6 keys, got %d", trie.Len())
+	}
+	for key, expected := range map[string]int{"profits": 0, "profits.revenue.net": 2, "losses.revenue.net": 3, "a-b": 10, "": 5} {
+		if found, val := trie.Search(key); !found || val != expected {
+			t.Errorf("expected %q to be %d, got %v %d", key, expected, found, val)
+		}
+	}
+	if found, _ := trie.Search("losses.revenue"); found {
+		t.Errorf("expected a node without a value not to be found")
+	}
+
+	expected := map[string]int{"profits": 0, "profits.revenue": 1, "profits.revenue.net": 2}
+	if results := trie.SearchPrefix("profits"); !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected %v, got %v", expected, results)
+	}
+
+	if !trie.Delete("losses.revenue.net") || trie.Delete("losses.revenue.net") {
+		t.Errorf("expected only the first delete to succeed")
+	}
+	// the freed nodes (and their chunk bytes) get reused
+	nodes, chunkBytes := len(trie.nodes), len(trie.chunks)
+	trie.Insert("gains.revenue.net", 20)
+	if len(trie.nodes) != nodes || len(trie.chunks) != chunkBytes {
+		t.Errorf("expected freed nodes to be reused, arena grew from %d to %d nodes", nodes, len(trie.nodes))
+	}
+	if found, val := trie.Search("gains.revenue.net"); !found || val != 20 {
+		t.Errorf("expected the new key to be found, got %v %d", found, val)
+	}
+}
+
+func TestArenaTrieHashCollisions(t *testing.T) {
+	trie := NewArena[int]()
+	trie.Insert("a", 1)
+	// pretend "b" hashes the same as "a"
+	_, hash, _ := trie.child(0, "a")
+	b := trie.newNode(0, "b", hash)
+	trie.nodes[b].Value, trie.nodes[b].HasValue = 2, true
+
+	if child, _, ok := trie.child(0, "a"); !ok || trie.nodes[child].Value != 1 {
+		t.Errorf("expected a to be found")
+	}
+	if i, ok := trie.collisions[arenaCollision{0, "b"}]; !ok || i != b {
+		t.Errorf("expected b to go into the collisions map")
+	}
+	// deleting a hands its slot over to b
+	trie.Delete("a")
+	if i, ok := trie.children[arenaEdge{0, hash}]; !ok || i != b || len(trie.collisions) != 0 {
+		t.Errorf("expected b to take over a's slot")
+	}
+}
+
+func TestChildSet(t *testing.T) {
+	trie := New[int]()
+	chunks := []string{"m", "c", "x", "a", "q", "e", "z", "b", "k", "h", "t"}
+	for i, chunk := range chunks {
+		trie.Insert("root."+chunk, i)
+		// check the set at every size, across the switch from one child to a slice and from a slice to a map
+		_, node := trie.Search("root")
+		if node.children.len() != i+1 {
+			t.Errorf("expected %d children, got %d", i+1, node.children.len())
+		}
+		for _, want := range chunks[:i+1] {
+			if found, child := trie.Search("root." + want); !found || child.Chunk != want {
+				t.Errorf("expected to find root.%s with %d children", want, i+1)
+			}
+		}
+	}
+	_, node := trie.Search("root")
+	if node.children.byChunk == nil {
+		t.Errorf("expected %d children to be in a map", len(chunks))
+	}
+
+	// sorted no matter how they're stored
+	var ordered []string
+	trie.Each(func(key string, val int) bool {
+		ordered = append(ordered, key)
+		return true
+	})
+	expected := []string{"root.a", "root.b", "root.c", "root.e", "root.h", "root.k", "root.m", "root.q", "root.t", "root.x", "root.z"}
+	if !reflect.DeepEqual(ordered, expected) {
+		t.Errorf("expected %v, got %v", expected, ordered)
+	}
+
+	// and back down again
+	for i, chunk := range chunks {
+		trie.Delete("root." + chunk)
+		if found, _ := trie.Search("root." + chunk); found {
+			t.Errorf("expected root.%s to be pruned", chunk)
+		}
+		if rest := len(chunks) - i - 1; rest > 0 && trie.Rank("root.zz") != rest {
+			t.Errorf("expected %d keys before root.zz, got %d", rest, trie.Rank("root.zz"))
+		}
+		if i == len(chunks)-2 && node.children.one == nil {
+			t.Errorf("expected the last child to be stored inline")
+		}
+	}
+	if found, _ := trie.Search("root"); found {
+		t.Errorf("expected root to be pruned along with its last child")
+	}
+}
+
+func TestInterned(t *testing.T) {
+	trie := NewInterned[int]()
+	trie.Insert("profits.revenue.net", 1)
+	trie.Insert("losses.revenue.net", 2)
+	trie.Insert("losses.revenue", 3)
+
+	_, profits := trie.Search("profits.revenue")
+	_, losses := trie.Search("losses.revenue")
+	if unsafe.StringData(profits.Chunk) != unsafe.StringData(losses.Chunk) {
+		t.Errorf("expected both revenue nodes to share one chunk")
+	}
+	if got := trie.chunks.chunks["revenue"].refs; got != 2 {
+		t.Errorf("expected revenue to be used by 2 nodes, got %d", got)
+	}
+	expected := map[string]int{"losses.revenue": 3, "losses.revenue.net": 2}
+	results := make(map[string]int)
+	for key, node := range trie.SearchPrefix("losses") {
+		results[key] = node.Value
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected %v, got %v", expected, results)
+	}
+
+	// pruning a node gives up its chunk, and chunks nobody uses anymore are dropped
+	trie.Delete("profits.revenue.net")
+	if _, ok := trie.chunks.chunks["profits"]; ok {
+		t.Errorf("expected profits to be dropped")
+	}
+	if got := trie.chunks.chunks["revenue"].refs; got != 1 {
+		t.Errorf("expected revenue to be used by 1 node, got %d", got)
+	}
+	trie.Delete("losses.revenue.net")
+	trie.Delete("losses.revenue")
+	if len(trie.chunks.chunks) != 0 {
+		t.Errorf("expected an empty trie to have no chunks, got %v", trie.chunks.chunks)
+	}
+}
+
+func TestStats(t *testing.T) {
+	trie := New[int]()
+	interned := NewInterned[int]()
+	arena := NewArena[int]()
+	for _, key := range []string{"profits.revenue", "profits.revenue.net", "losses.revenue"} {
+		trie.Insert(key, 1)
+		interned.Insert(key, 1)
+		arena.Insert(key, 1)
+	}
+	// deleted nodes shouldn't be counted
+	arena.Insert("gains.revenue.net", 1)
+	arena.Delete("gains.revenue.net")
+
+	all := map[string]stats.Stats{"trie": trie.Stats(), "interned": interned.Stats(), "arena": arena.Stats(), "frozen": trie.Freeze().Stats()}
+	for name, st := range all {
+		if st.Keys != 3 || st.Nodes != 6 || st.ValuelessNodes != 2 {
+			t.Errorf("%s: expected 3 keys in 6 nodes with 2 valueless ones, got %+v", name, st)
+		}
+		if st.MaxDepth != 3 || st.AvgDepth != 7.0/3 {
+			t.Errorf("%s: expected depths of 3 and 7/3, got %d and %v", name, st.MaxDepth, st.AvgDepth)
+		}
+		expected := map[int]int{0: 2, 1: 3, 2: 1}
+		if !reflect.DeepEqual(st.Fanout, expected) {
+			t.Errorf("%s: expected fanout %v, got %v", name, expected, st.Fanout)
+		}
+		if st.NodeBytes <= 0 || st.StringBytes <= 0 || st.ValueBytes <= 0 {
+			t.Errorf("%s: unexpected bytes %+v", name, st)
+		}
+	}
+	if got := all["trie"].StringBytes; got != len("profits")+2*len("revenue")+len("net")+len("losses") {
+		t.Errorf("expected every node's chunk to be counted, got %d bytes", got)
+	}
+	// only the root has more than one child, and two fit in a small slice
+	if got := all["trie"].ChildrenBytes; got != 2*int(unsafe.Sizeof(trie.root)) {
+		t.Errorf("expected only the root's children to take up space, got %d bytes", got)
+	}
+}
+
+func TestWalkAllocations(t *testing.T) {
+	visitor := func(key []byte, val int, hasValue bool) WalkAction { return Continue }
+	// nodes with one child (inline), a few (sorted slice) and lots (map) all walk their children without allocating.
+	// Walking from the root only allocates the key buffer, and any other prefix is split into chunks first.
+	for _, width := range []int{1, 5, 50} {
+		trie := New[int]()
+		for i := 0; i < 2000; i++ {
+			trie.Insert(fmt.Sprintf("svc_%d.host_%d.cpu", i%width, i), i)
+		}
+		for prefix, expected := range map[string]float64{"": 1, "svc_0": 2} {
+			if allocs := testing.AllocsPerRun(10, func() { trie.Walk(prefix, visitor) }); allocs > expected {
+				t.Errorf("expected %v allocations to walk %q with %d services, got %v", expected, prefix, width, allocs)
+			}
+		}
+	}
+}
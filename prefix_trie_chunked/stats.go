@@ -0,0 +1,93 @@
+package prefix_trie_chunked
+
+import (
+	"unsafe"
+
+	"github.com/groovemonkey/trie-keys-experiment/stats"
+)
+
+// Stats walks the whole trie and returns its Stats.
+// Without interning, chunks are counted once per node even though nodes made by the same Insert share their key's bytes.
+func (t *Trie[T]) Stats() stats.Stats {
+	var zero T
+	st := stats.New()
+	var visit func(node *trieNode[T], depth int)
+	visit = func(node *trieNode[T], depth int) {
+		st.AddNode(depth, node.children.len(), node.HasValue)
+		st.ChildrenBytes += node.children.bytes()
+		if t.chunks == nil {
+			st.StringBytes += len(node.Chunk)
+		}
+		node.children.each(func(child *trieNode[T]) bool {
+			visit(child, depth+1)
+			return true
+		})
+	}
+	visit(t.root, 0)
+	st.Finish()
+	if t.chunks != nil {
+		for chunk := range t.chunks.chunks {
+			st.StringBytes += len(chunk)
+		}
+		st.StringBytes += stats.MapBytes(len(t.chunks.chunks), unsafe.Sizeof("")+unsafe.Sizeof(internedChunk{}))
+	}
+	st.ValueBytes = st.Nodes * int(unsafe.Sizeof(zero))
+	st.NodeBytes = st.Nodes*int(unsafe.Sizeof(trieNode[T]{})) - st.ValueBytes
+	return st
+}
+
+// bytes is how much the set allocates on top of the childSet struct itself
+func (c *childSet[T]) bytes() int {
+	switch {
+	case c.byChunk != nil:
+		return stats.MapBytes(len(c.byChunk), unsafe.Sizeof("")+unsafe.Sizeof(c.one))
+	case c.sorted != nil:
+		return cap(c.sorted) * int(unsafe.Sizeof(c.one))
+	}
+	return 0
+}
+
+// Stats walks the whole trie and returns its Stats.
+// The bytes count the whole node slice and chunk slab, including free nodes and spare capacity, since that's what's allocated.
+func (t *ArenaTrie[T]) Stats() stats.Stats {
+	var zero T
+	st := stats.New()
+	var visit func(i uint32, depth int)
+	visit = func(i uint32, depth int) {
+		numChildren := 0
+		for c := t.nodes[i].firstChild; c != 0; c = t.nodes[c].nextSibling {
+			numChildren++
+			visit(c, depth+1)
+		}
+		st.AddNode(depth, numChildren, t.nodes[i].HasValue)
+	}
+	visit(0, 0)
+	st.Finish()
+	st.ChildrenBytes = stats.MapBytes(len(t.children), unsafe.Sizeof(arenaEdge{})+unsafe.Sizeof(uint32(0)))
+	st.ChildrenBytes += stats.MapBytes(len(t.collisions), unsafe.Sizeof(arenaCollision{})+unsafe.Sizeof(uint32(0)))
+	st.StringBytes = cap(t.chunks)
+	st.ValueBytes = cap(t.nodes) * int(unsafe.Sizeof(zero))
+	st.NodeBytes = cap(t.nodes)*int(unsafe.Sizeof(arenaNode[T]{})) - st.ValueBytes
+	return st
+}
+
+// Stats returns the frozen trie's Stats. Children sit next to each other in the node slice, so nothing links them,
+// and only nodes with a value have a value slot.
+func (f *FrozenTrie[T]) Stats() stats.Stats {
+	var zero T
+	st := stats.New()
+	var visit func(i uint32, depth int)
+	visit = func(i uint32, depth int) {
+		node := f.nodes[i]
+		st.AddNode(depth, int(node.numChildren), node.hasValue)
+		for c := node.firstChild; c < node.firstChild+node.numChildren; c++ {
+			visit(c, depth+1)
+		}
+	}
+	visit(0, 0)
+	st.Finish()
+	st.StringBytes = len(f.chunkData) + cap(f.chunkOffsets)*int(unsafe.Sizeof(uint32(0)))
+	st.ValueBytes = cap(f.values) * int(unsafe.Sizeof(zero))
+	st.NodeBytes = cap(f.nodes) * int(unsafe.Sizeof(frozenNode{}))
+	return st
+}
@@ -0,0 +1,54 @@
+package prefix_trie_chunked
+
+import "golang.org/x/exp/constraints"
+
+// Number is what Increment can add up
+type Number interface {
+	constraints.Integer | constraints.Float
+}
+
+// Update sets key to whatever fn returns, given the current value (and whether there is one), in a single traversal
+func (t *Trie[T]) Update(key string, fn func(old T, exists bool) T) T {
+	var pathBuf [32]*trieNode[T]
+	node, path := t.findOrCreate(key, pathBuf[:0])
+	newVal := fn(node.Value, node.HasValue)
+	t.setValue(path, node, newVal)
+	return newVal
+}
+
+// LoadOrStore returns the existing value for key if there is one (and loaded=true).
+// Otherwise it stores val and returns it.
+func (t *Trie[T]) LoadOrStore(key string, val T) (actual T, loaded bool) {
+	var pathBuf [32]*trieNode[T]
+	node, path := t.findOrCreate(key, pathBuf[:0])
+	if node.HasValue {
+		return node.Value, true
+	}
+	t.setValue(path, node, val)
+	return val, false
+}
+
+// Increment adds delta to the value for key (starting from zero if there isn't one) and returns the new value.
+// It's a function rather than a method because it only works for numeric tries.
+func Increment[T Number](t *Trie[T], key string, delta T) T {
+	var pathBuf [32]*trieNode[T]
+	node, path := t.findOrCreate(key, pathBuf[:0])
+	var current T
+	if node.HasValue {
+		current = node.Value
+	}
+	t.setValue(path, node, current+delta)
+	return node.Value
+}
+
+// CompareAndSwap sets key to new only if it currently holds old, and reports whether it did.
+// Missing keys are never swapped. It's a function rather than a method because T has to be comparable.
+func CompareAndSwap[T comparable](t *Trie[T], key string, old, new T) bool {
+	found, node := t.Search(key)
+	if !found || !node.HasValue || node.Value != old {
+		return false
+	}
+	node.Value = new
+	t.touch(node)
+	return true
+}
@@ -0,0 +1,298 @@
+package skip_list
+
+import (
+	"math/bits"
+	"math/rand"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// List is a concurrent skip list ("lazy" skip list, Herlihy & Shavit): writers only lock the few nodes
+// right before the key they're changing, and readers don't lock at all. Many goroutines can insert,
+// delete and search at once without a global lock.
+//
+// A node is only considered part of the list once it's fullyLinked at every level, and a delete marks
+// the node before unlinking it, so a reader that runs into a node halfway through either change can
+// tell whether to count it.
+
+// maxLevel is enough for 2^32 keys with a 1/2 chance of going up a level
+const maxLevel = 32
+
+type node[T any] struct {
+	key   string
+	value atomic.Pointer[T]
+	// next[i] is the next node at level i; the node is on levels 0..len(next)-1
+	next        []atomic.Pointer[node[T]]
+	mu          sync.Mutex
+	marked      atomic.Bool
+	fullyLinked atomic.Bool
+}
+
+type List[T any] struct {
+	// head is a sentinel that sorts before every key (including ""), its key is never compared
+	head   *node[T]
+	length atomic.Int64
+}
+
+func New[T any]() *List[T] {
+	head := &node[T]{next: make([]atomic.Pointer[node[T]], maxLevel)}
+	head.fullyLinked.Store(true)
+	return &List[T]{head: head}
+}
+
+// randomLevel picks how many levels a new node is on: 1 with probability 1/2, 2 with 1/4, and so on
+func randomLevel() int {
+	// the global math/rand functions are safe for concurrent use
+	return 1 + bits.TrailingZeros64(rand.Uint64()|1<<(maxLevel-1))
+}
+
+// find fills preds and succs with the nodes right before and at-or-after key on every level.
+// It returns the highest level key was found on, or -1.
+func (l *List[T]) find(key string, preds, succs *[maxLevel]*node[T]) int {
+	found := -1
+	pred := l.head
+	for level := maxLevel - 1; level >= 0; level-- {
+		curr := pred.next[level].Load()
+		for curr != nil && curr.key < key {
+			pred = curr
+			curr = pred.next[level].Load()
+		}
+		if found == -1 && curr != nil && curr.key == key {
+			found = level
+		}
+		preds[level] = pred
+		succs[level] = curr
+	}
+	return found
+}
+
+// Len returns the number of keys
+func (l *List[T]) Len() int {
+	return int(l.length.Load())
+}
+
+// Search returns whether key exists, and its value. It never blocks.
+func (l *List[T]) Search(key string) (bool, T) {
+	var preds, succs [maxLevel]*node[T]
+	var zero T
+	level := l.find(key, &preds, &succs)
+	if level == -1 {
+		return false, zero
+	}
+	n := succs[level]
+	if !n.fullyLinked.Load() || n.marked.Load() {
+		return false, zero
+	}
+	return true, *n.value.Load()
+}
+
+// Insert sets key to val, adding key if it isn't there yet
+func (l *List[T]) Insert(key string, val T) {
+	l.upsert(key, func(T, bool) (T, bool) { return val, true })
+}
+
+// upsert is the one way writes change a key: it calls fn with key's current value (and whether there is one),
+// and stores what fn returns if fn also returns true. It returns fn's value and whether the key existed.
+// fn runs while the key is locked against every other write (its node's lock, or its predecessors' locks if
+// it's new), so read-modify-writes are atomic. That also means fn has to be quick and mustn't use the list.
+func (l *List[T]) upsert(key string, fn func(old T, exists bool) (T, bool)) (T, bool) {
+	var preds, succs [maxLevel]*node[T]
+	topLevel := randomLevel()
+	for {
+		if level := l.find(key, &preds, &succs); level != -1 {
+			n := succs[level]
+			if n.marked.Load() {
+				// it's being deleted, try again once it's gone
+				continue
+			}
+			// someone else is still linking it in, wait until it's really there
+			for !n.fullyLinked.Load() {
+				runtime.Gosched()
+			}
+			// Delete marks nodes with their lock held, so an unmarked node stays in the list until we unlock it
+			n.mu.Lock()
+			if n.marked.Load() {
+				n.mu.Unlock()
+				continue
+			}
+			val, store := fn(*n.value.Load(), true)
+			if store {
+				n.value.Store(&val)
+			}
+			n.mu.Unlock()
+			return val, true
+		}
+
+		// lock the predecessors bottom up and make sure nothing changed between them and their successors
+		highestLocked := -1
+		valid := true
+		var prevPred *node[T]
+		for level := 0; valid && level < topLevel; level++ {
+			pred, succ := preds[level], succs[level]
+			if pred != prevPred {
+				pred.mu.Lock()
+				highestLocked = level
+				prevPred = pred
+			}
+			valid = !pred.marked.Load() && (succ == nil || !succ.marked.Load()) && pred.next[level].Load() == succ
+		}
+		if !valid {
+			unlockPreds(&preds, highestLocked)
+			continue
+		}
+
+		// nobody can add key while we hold its level 0 predecessor
+		var zero T
+		val, store := fn(zero, false)
+		if !store {
+			unlockPreds(&preds, highestLocked)
+			return val, false
+		}
+		n := &node[T]{key: key, next: make([]atomic.Pointer[node[T]], topLevel)}
+		n.value.Store(&val)
+		for level := 0; level < topLevel; level++ {
+			n.next[level].Store(succs[level])
+		}
+		for level := 0; level < topLevel; level++ {
+			preds[level].next[level].Store(n)
+		}
+		n.fullyLinked.Store(true)
+		unlockPreds(&preds, highestLocked)
+		l.length.Add(1)
+		return val, false
+	}
+}
+
+// unlockPreds unlocks preds[0..highest], each node once
+func unlockPreds[T any](preds *[maxLevel]*node[T], highest int) {
+	var prevPred *node[T]
+	for level := 0; level <= highest; level++ {
+		if preds[level] != prevPred {
+			preds[level].mu.Unlock()
+			prevPred = preds[level]
+		}
+	}
+}
+
+// Delete removes key and reports whether it was there
+func (l *List[T]) Delete(key string) bool {
+	var preds, succs [maxLevel]*node[T]
+	var victim *node[T]
+	isMarked := false
+	for {
+		level := l.find(key, &preds, &succs)
+		if !isMarked {
+			// only delete nodes that are completely linked in, and found on their top level
+			// (otherwise find ran into them halfway through being linked or unlinked)
+			if level == -1 {
+				return false
+			}
+			victim = succs[level]
+			if !victim.fullyLinked.Load() || len(victim.next)-1 != level || victim.marked.Load() {
+				return false
+			}
+			victim.mu.Lock()
+			if victim.marked.Load() {
+				victim.mu.Unlock()
+				return false
+			}
+			// from here on readers treat it as gone
+			victim.marked.Store(true)
+			isMarked = true
+		}
+
+		topLevel := len(victim.next)
+		highestLocked := -1
+		valid := true
+		var prevPred *node[T]
+		for level := 0; valid && level < topLevel; level++ {
+			pred := preds[level]
+			if pred != prevPred {
+				pred.mu.Lock()
+				highestLocked = level
+				prevPred = pred
+			}
+			valid = !pred.marked.Load() && pred.next[level].Load() == victim
+		}
+		if !valid {
+			unlockPreds(&preds, highestLocked)
+			continue
+		}
+		for level := topLevel - 1; level >= 0; level-- {
+			preds[level].next[level].Store(victim.next[level].Load())
+		}
+		victim.mu.Unlock()
+		unlockPreds(&preds, highestLocked)
+		l.length.Add(-1)
+		return true
+	}
+}
+
+// KeyValue is a single key and its value, used wherever results need a stable order
+type KeyValue[T any] struct {
+	Key   string
+	Value T
+}
+
+// scan calls fn for every key >= startKey in order, stopping early if fn returns false.
+// It doesn't lock anything, so it's weakly consistent: keys inserted or deleted during the scan may or may not be seen,
+// but every key that's there for the whole scan is.
+func (l *List[T]) scan(startKey string, fn func(key string, val T) bool) {
+	// find the last node before startKey, going down the levels
+	pred := l.head
+	for level := maxLevel - 1; level >= 0; level-- {
+		for curr := pred.next[level].Load(); curr != nil && curr.key < startKey; curr = pred.next[level].Load() {
+			pred = curr
+		}
+	}
+	for curr := pred.next[0].Load(); curr != nil; curr = curr.next[0].Load() {
+		if !curr.fullyLinked.Load() || curr.marked.Load() {
+			continue
+		}
+		if !fn(curr.key, *curr.value.Load()) {
+			return
+		}
+	}
+}
+
+// Each calls fn for every key in lexicographic order, stopping early if fn returns false
+func (l *List[T]) Each(fn func(key string, val T) bool) {
+	l.scan("", fn)
+}
+
+// Range returns all keys k with startKey <= k < endKey, in lexicographic order.
+// An empty endKey means "no upper bound".
+func (l *List[T]) Range(startKey, endKey string) []KeyValue[T] {
+	var results []KeyValue[T]
+	l.scan(startKey, func(key string, val T) bool {
+		if endKey != "" && key >= endKey {
+			return false
+		}
+		results = append(results, KeyValue[T]{Key: key, Value: val})
+		return true
+	})
+	return results
+}
+
+// SearchPrefixFunc calls fn for every key with the given prefix in lexicographic order, stopping early if fn returns false
+func (l *List[T]) SearchPrefixFunc(prefix string, fn func(key string, val T) bool) {
+	l.scan(prefix, func(key string, val T) bool {
+		// keys with the prefix are all next to each other
+		return strings.HasPrefix(key, prefix) && fn(key, val)
+	})
+}
+
+// SearchPrefix returns all keys with the given prefix, mapped to their values
+func (l *List[T]) SearchPrefix(prefix string) map[string]T {
+	results := make(map[string]T)
+	if len(prefix) == 0 {
+		return results
+	}
+	l.SearchPrefixFunc(prefix, func(key string, val T) bool {
+		results[key] = val
+		return true
+	})
+	return results
+}
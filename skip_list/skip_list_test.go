@@ -0,0 +1,189 @@
+package skip_list
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"unsafe"
+)
+
+func TestInsertSearchDelete(t *testing.T) {
+	list := New[int]()
+	for i, key := range []string{"b", "a", "", "profits.revenue", "profits", "héllo"} {
+		list.Insert(key, i)
+	}
+	list.Insert("a", 10)
+
+	if list.Len() != 6 {
+		t.Errorf("expected 6 keys, got %d", list.Len())
+	}
+	for key, expected := range map[string]int{"b": 0, "a": 10, "": 2, "profits": 4, "héllo": 5} {
+		if found, val := list.Search(key); !found || val != expected {
+			t.Errorf("expected %q to be %d, got %v %d", key, expected, found, val)
+		}
+	}
+	if found, _ := list.Search("prof"); found {
+		t.Errorf("expected prof not to be found")
+	}
+
+	if !list.Delete("a") || list.Delete("a") || list.Delete("nope") {
+		t.Errorf("expected only the first delete of a to succeed")
+	}
+	if found, _ := list.Search("a"); found || list.Len() != 5 {
+		t.Errorf("expected a to be gone")
+	}
+}
+
+func TestRangeAndPrefix(t *testing.T) {
+	list := New[int]()
+	for i, key := range []string{"aa", "ab", "abc", "b", "ba", "c", "profits", "profits.revenue", "profits.loss", "profitz"} {
+		list.Insert(key, i)
+	}
+
+	results := list.Range("ab", "ba")
+	expected := []KeyValue[int]{{"ab", 1}, {"abc", 2}, {"b", 3}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected Range to return %v, got %v", expected, results)
+	}
+
+	prefixResults := list.SearchPrefix("profits")
+	expectedPrefix := map[string]int{"profits": 6, "profits.revenue": 7, "profits.loss": 8}
+	if !reflect.DeepEqual(prefixResults, expectedPrefix) {
+		t.Errorf("expected %v, got %v", expectedPrefix, prefixResults)
+	}
+	if results := list.SearchPrefix("nope"); len(results) != 0 {
+		t.Errorf("expected no results, got %v", results)
+	}
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	list := New[int]()
+	const goroutines = 8
+	const perGoroutine = 1000
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				// every goroutine writes the shared keys, and its own ones
+				list.Insert(fmt.Sprintf("shared.%d", i), i)
+				list.Insert(fmt.Sprintf("own.%d.%d", g, i), i)
+				if i%2 == 0 {
+					list.Delete(fmt.Sprintf("own.%d.%d", g, i))
+				}
+				list.SearchPrefix(fmt.Sprintf("own.%d.", g))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	expectedLen := perGoroutine + goroutines*perGoroutine/2
+	if list.Len() != expectedLen {
+		t.Errorf("expected %d keys, got %d", expectedLen, list.Len())
+	}
+	count := 0
+	prev := ""
+	list.Each(func(key string, val int) bool {
+		if count > 0 && key <= prev {
+			t.Errorf("expected keys in order, got %q after %q", key, prev)
+		}
+		prev = key
+		count++
+		return true
+	})
+	if count != expectedLen {
+		t.Errorf("expected to iterate over %d keys, got %d", expectedLen, count)
+	}
+}
+
+func TestReadModifyWrite(t *testing.T) {
+	list := New[int]()
+	if Increment(list, "count", 2) != 2 || Increment(list, "count", 3) != 5 {
+		t.Errorf("expected increments to add up")
+	}
+	if CompareAndSwap(list, "count", 4, 10) || !CompareAndSwap(list, "count", 5, 10) {
+		t.Errorf("expected only the matching CompareAndSwap to succeed")
+	}
+	if CompareAndSwap(list, "missing", 0, 1) {
+		t.Errorf("expected CompareAndSwap not to swap a missing key")
+	}
+	if val, loaded := list.LoadOrStore("count", 1); !loaded || val != 10 {
+		t.Errorf("expected LoadOrStore to load 10, got %d %v", val, loaded)
+	}
+	if val, loaded := list.LoadOrStore("new", 1); loaded || val != 1 {
+		t.Errorf("expected LoadOrStore to store 1, got %d %v", val, loaded)
+	}
+	if val := list.Update("new", func(old int, exists bool) int { return old * 7 }); val != 7 {
+		t.Errorf("expected Update to return 7, got %d", val)
+	}
+	if list.Len() != 2 {
+		t.Errorf("expected 2 keys, got %d", list.Len())
+	}
+}
+
+func TestConcurrentReadModifyWrite(t *testing.T) {
+	list := New[int]()
+	var wg sync.WaitGroup
+	var stored [8]bool
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			_, loaded := list.LoadOrStore("once", g)
+			stored[g] = !loaded
+			for i := 0; i < 1000; i++ {
+				Increment(list, fmt.Sprintf("count.%d", i%10), 1)
+				// a delete can't lose an increment, it just starts that key over
+				if g == 0 && i%100 == 0 {
+					list.Delete("scratch")
+				}
+				Increment(list, "scratch", 1)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	stores := 0
+	for _, s := range stored {
+		if s {
+			stores++
+		}
+	}
+	if stores != 1 {
+		t.Errorf("expected exactly one LoadOrStore to store, got %d", stores)
+	}
+	for i := 0; i < 10; i++ {
+		if found, val := list.Search(fmt.Sprintf("count.%d", i)); !found || val != 800 {
+			t.Errorf("expected count.%d to be 800, got %v %d", i, found, val)
+		}
+	}
+	if list.Len() != 12 {
+		t.Errorf("expected 12 keys, got %d", list.Len())
+	}
+}
+
+func TestStats(t *testing.T) {
+	list := New[int]()
+	for i := 0; i < 1000; i++ {
+		list.Insert(fmt.Sprintf("key%04d", i), i)
+	}
+	list.Delete("key0000")
+
+	stats := list.Stats()
+	if stats.Keys != 999 || stats.Nodes != 1000 || stats.ValuelessNodes != 0 || stats.StringBytes != 999*len("key0000") {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	// about half the nodes are on one level, a quarter on two, ...
+	if stats.Fanout[1] < 350 || stats.Fanout[1] > 650 || stats.Fanout[maxLevel] != 1 {
+		t.Errorf("expected about half the nodes on one level, got %v", stats.Fanout)
+	}
+	if stats.AvgDepth < 1.5 || stats.AvgDepth > 2.5 || stats.MaxDepth < 5 {
+		t.Errorf("expected about 2 levels per node, got %v (max %d)", stats.AvgDepth, stats.MaxDepth)
+	}
+	if stats.NodeBytes <= 0 || stats.ChildrenBytes <= 0 || stats.ValueBytes != 999*int(unsafe.Sizeof(0)) {
+		t.Errorf("unexpected bytes %+v", stats)
+	}
+}
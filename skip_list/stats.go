@@ -0,0 +1,33 @@
+package skip_list
+
+import (
+	"unsafe"
+
+	"github.com/groovemonkey/trie-keys-experiment/stats"
+)
+
+// Stats walks the bottom level of the list and returns its Stats. Like scan it doesn't lock, so it's only
+// exact if nothing writes while it runs.
+// A skip list has no tree shape, so depth and fanout describe the towers instead: a node's "children" are its
+// forward links, one per level it's on, and a key's depth is its number of levels. The head is the root.
+func (l *List[T]) Stats() stats.Stats {
+	var zero T
+	st := stats.Stats{Nodes: 1, Fanout: make(map[int]int)}
+	st.Fanout[len(l.head.next)]++
+	st.ChildrenBytes = cap(l.head.next) * int(unsafe.Sizeof(l.head.next[0]))
+	for curr := l.head.next[0].Load(); curr != nil; curr = curr.next[0].Load() {
+		if !curr.fullyLinked.Load() || curr.marked.Load() {
+			continue
+		}
+		levels := len(curr.next)
+		st.Nodes++
+		st.Fanout[levels]++
+		st.AddKeys(levels, 1)
+		st.ChildrenBytes += cap(curr.next) * int(unsafe.Sizeof(curr.next[0]))
+		st.StringBytes += len(curr.key)
+	}
+	st.Finish()
+	st.NodeBytes = st.Nodes * int(unsafe.Sizeof(node[T]{}))
+	st.ValueBytes = st.Keys * int(unsafe.Sizeof(zero))
+	return st
+}
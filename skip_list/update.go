@@ -0,0 +1,45 @@
+package skip_list
+
+import "golang.org/x/exp/constraints"
+
+// Number is what Increment can add up
+type Number interface {
+	constraints.Integer | constraints.Float
+}
+
+// Update sets key to whatever fn returns, given the current value (and whether there is one), in a single traversal.
+// It's atomic: no other write to key can happen between reading the old value and storing the new one.
+// fn runs with the key locked, so it has to be quick and mustn't use the list.
+func (l *List[T]) Update(key string, fn func(old T, exists bool) T) T {
+	val, _ := l.upsert(key, func(old T, exists bool) (T, bool) { return fn(old, exists), true })
+	return val
+}
+
+// LoadOrStore returns the existing value for key if there is one (and loaded=true).
+// Otherwise it stores val and returns it. Of several goroutines storing the same new key at once, only one stores.
+func (l *List[T]) LoadOrStore(key string, val T) (actual T, loaded bool) {
+	return l.upsert(key, func(old T, exists bool) (T, bool) {
+		if exists {
+			return old, false
+		}
+		return val, true
+	})
+}
+
+// Increment adds delta to the value for key (starting from zero if there isn't one) and returns the new value.
+// Concurrent increments never lose each other's deltas.
+// It's a function rather than a method because it only works for numeric lists.
+func Increment[T Number](l *List[T], key string, delta T) T {
+	return l.Update(key, func(old T, exists bool) T { return old + delta })
+}
+
+// CompareAndSwap sets key to new only if it currently holds old, and reports whether it did.
+// Missing keys are never swapped. It's a function rather than a method because T has to be comparable.
+func CompareAndSwap[T comparable](l *List[T], key string, old, new T) bool {
+	swapped := false
+	l.upsert(key, func(current T, exists bool) (T, bool) {
+		swapped = exists && current == old
+		return new, swapped
+	})
+	return swapped
+}
@@ -0,0 +1,75 @@
+package sorted_array
+
+import (
+	"sort"
+	"strings"
+)
+
+// PageOptions controls which slice of a prefix search SearchPrefixPage returns
+type PageOptions struct {
+	// maximum number of results to return, 0 means no limit
+	Limit int
+	// number of matching keys to skip before collecting results
+	Offset int
+	// continuation token returned by a previous page: only keys after it are returned
+	After string
+}
+
+// SearchPrefixFunc calls fn for every key with the given prefix in lexicographic order, stopping early if fn returns false
+func (s *Store[T]) SearchPrefixFunc(prefix string, fn func(key string, val T) bool) {
+	for c := s.cursor(prefix, prefix); c.Valid() && strings.HasPrefix(c.Key(), prefix); c.Next() {
+		if !fn(c.Key(), c.Value()) {
+			return
+		}
+	}
+}
+
+// SearchPrefixPage returns one sorted page of the keys with the given prefix, and a continuation token for the next page.
+// The token is empty when there are no more results. Pass it back in PageOptions.After to continue.
+// Finding the page is two binary searches, no matter how deep into the results it is, unless there are buffered
+// writes under the prefix: they shift the positions, so then it walks the results from After.
+func (s *Store[T]) SearchPrefixPage(prefix string, opts PageOptions) ([]KeyValue[T], string) {
+	if len(s.pending(prefix, prefix)) > 0 {
+		return s.walkPage(prefix, opts)
+	}
+	lo, hi := s.prefixRange(prefix)
+	start := lo
+	if opts.After != "" {
+		start = lo + sort.Search(hi-lo, func(i int) bool { return s.keys[lo+i] > opts.After })
+	}
+	start += opts.Offset
+	if start >= hi {
+		return nil, ""
+	}
+
+	end := hi
+	nextToken := ""
+	if opts.Limit > 0 && end-start > opts.Limit {
+		end = start + opts.Limit
+		nextToken = s.keys[end-1]
+	}
+	results := make([]KeyValue[T], 0, end-start)
+	for i := start; i < end; i++ {
+		results = append(results, KeyValue[T]{Key: s.keys[i], Value: s.values[i]})
+	}
+	return results, nextToken
+}
+
+// walkPage is SearchPrefixPage with buffered writes merged in, one key at a time
+func (s *Store[T]) walkPage(prefix string, opts PageOptions) ([]KeyValue[T], string) {
+	c := s.cursor(max(prefix, opts.After), prefix)
+	if c.Valid() && c.Key() == opts.After {
+		c.Next()
+	}
+	for i := 0; i < opts.Offset && c.Valid(); i++ {
+		c.Next()
+	}
+	var results []KeyValue[T]
+	for ; c.Valid() && strings.HasPrefix(c.Key(), prefix); c.Next() {
+		if opts.Limit > 0 && len(results) == opts.Limit {
+			return results, results[len(results)-1].Key
+		}
+		results = append(results, KeyValue[T]{Key: c.Key(), Value: c.Value()})
+	}
+	return results, ""
+}
@@ -0,0 +1,366 @@
+package sorted_array
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Store keeps its keys in one sorted slice (and the values in a parallel one), so every prefix is a
+// contiguous run of keys that two binary searches can find. Inserting into the middle of a big slice is
+// expensive though, so writes go into a small buffer first and get merged into the slices in one go
+// once the buffer fills up.
+// Reads never merge: they combine the buffer with the sorted keys as they go, so they don't change the store
+// and any number of them can run at once. Writes still need exclusive access.
+type Store[T Number] struct {
+	keys   []string
+	values []T
+	// writes that haven't been merged into keys/values yet
+	buffer map[string]bufferedWrite[T]
+	// number of keys, counting the buffered writes
+	numKeys int
+}
+
+type bufferedWrite[T Number] struct {
+	value T
+	// a delete of a key that's in keys
+	deleted bool
+}
+
+type Number interface {
+	constraints.Integer | constraints.Float
+}
+
+// Aggregation function interface: take any number of keys and aggregate them somehow (sum, mean, etc.)
+// The result will always be a float64
+type AggregationFunction[T Number] func(keysAndVals map[string]T) T
+
+// minBufferSize is the smallest number of buffered writes that triggers a merge.
+// Bigger stores buffer more (an eighth of their size), so each key is copied a bounded number of times on average.
+const minBufferSize = 256
+
+func New[T Number]() *Store[T] {
+	return &Store[T]{buffer: make(map[string]bufferedWrite[T])}
+}
+
+// find returns where key is (or would be) in the sorted keys
+func (s *Store[T]) find(key string) (int, bool) {
+	i := sort.SearchStrings(s.keys, key)
+	return i, i < len(s.keys) && s.keys[i] == key
+}
+
+func (s *Store[T]) Insert(key string, value T) {
+	if exists, _ := s.Search(key); !exists {
+		s.numKeys++
+	}
+	s.buffer[key] = bufferedWrite[T]{value: value}
+	s.maybeMerge()
+}
+
+// Delete removes key from the store and reports whether it was there
+func (s *Store[T]) Delete(key string) bool {
+	if w, ok := s.buffer[key]; ok {
+		if w.deleted {
+			return false
+		}
+		if _, inKeys := s.find(key); !inKeys {
+			// it only ever lived in the buffer
+			delete(s.buffer, key)
+			s.numKeys--
+			return true
+		}
+	} else if _, inKeys := s.find(key); !inKeys {
+		return false
+	}
+	s.numKeys--
+	s.buffer[key] = bufferedWrite[T]{deleted: true}
+	s.maybeMerge()
+	return true
+}
+
+func (s *Store[T]) Search(key string) (bool, T) {
+	var defaultResult T
+	if w, ok := s.buffer[key]; ok {
+		if w.deleted {
+			return false, defaultResult
+		}
+		return true, w.value
+	}
+	i, ok := s.find(key)
+	if !ok {
+		return false, defaultResult
+	}
+	return true, s.values[i]
+}
+
+func (s *Store[T]) maybeMerge() {
+	if len(s.buffer) >= max(minBufferSize, len(s.keys)/8) {
+		s.merge()
+	}
+}
+
+// merge applies the buffered writes to keys/values.
+// It builds new slices rather than shifting the old ones, so Cursors keep working on the keys they started with.
+func (s *Store[T]) merge() {
+	if len(s.buffer) == 0 {
+		return
+	}
+	pending := make([]string, 0, len(s.buffer))
+	for key := range s.buffer {
+		pending = append(pending, key)
+	}
+	sort.Strings(pending)
+
+	keys := make([]string, 0, len(s.keys)+len(pending))
+	values := make([]T, 0, len(s.keys)+len(pending))
+	i := 0
+	for _, key := range pending {
+		// copy everything before the buffered key unchanged
+		for ; i < len(s.keys) && s.keys[i] < key; i++ {
+			keys = append(keys, s.keys[i])
+			values = append(values, s.values[i])
+		}
+		// the buffered write replaces the old value
+		if i < len(s.keys) && s.keys[i] == key {
+			i++
+		}
+		if w := s.buffer[key]; !w.deleted {
+			keys = append(keys, key)
+			values = append(values, w.value)
+		}
+	}
+	keys = append(keys, s.keys[i:]...)
+	values = append(values, s.values[i:]...)
+
+	s.keys, s.values = keys, values
+	clear(s.buffer)
+}
+
+// Len returns the number of keys
+func (s *Store[T]) Len() int {
+	return s.numKeys
+}
+
+// prefixRange returns the range of indexes of the sorted keys with the given prefix, not counting the buffer
+func (s *Store[T]) prefixRange(prefix string) (int, int) {
+	lo := sort.SearchStrings(s.keys, prefix)
+	// keys with the prefix come first, so the end is where they stop having it
+	hi := lo + sort.Search(len(s.keys)-lo, func(i int) bool { return !strings.HasPrefix(s.keys[lo+i], prefix) })
+	return lo, hi
+}
+
+func (s *Store[T]) SearchPrefix(prefix string) map[string]T {
+	results := make(map[string]T)
+	lo, hi := s.prefixRange(prefix)
+	for i := lo; i < hi; i++ {
+		results[s.keys[i]] = s.values[i]
+	}
+	// buffered writes replace what's in keys
+	for key, w := range s.buffer {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if w.deleted {
+			delete(results, key)
+		} else {
+			results[key] = w.value
+		}
+	}
+	return results
+}
+
+// pendingWrite is a buffered write along with its key, so a Cursor can keep them sorted
+type pendingWrite[T Number] struct {
+	key string
+	bufferedWrite[T]
+}
+
+// pending returns the buffered writes to keys >= start with the given prefix, sorted by key
+func (s *Store[T]) pending(start, prefix string) []pendingWrite[T] {
+	var pending []pendingWrite[T]
+	for key, w := range s.buffer {
+		if key >= start && strings.HasPrefix(key, prefix) {
+			pending = append(pending, pendingWrite[T]{key, w})
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].key < pending[j].key })
+	return pending
+}
+
+// AggregateDescendants aggregates values for all keys with a certain prefix.
+// It returns a bool indicating whether or not the result is valid (or just a meaningless float64 zero value), and a float64
+func (s *Store[T]) AggregateDescendants(prefix string, aggFunc AggregationFunction[T]) (bool, float64) {
+	// get descendants with a prefix search
+	descendants := s.SearchPrefix(prefix)
+	if len(descendants) == 0 {
+		return false, 0
+	}
+
+	// apply aggregation function
+	return true, float64(aggFunc(descendants))
+}
+
+func Sum[T Number](keysAndVals map[string]T) T {
+	var sum T
+	for _, v := range keysAndVals {
+		sum += v
+	}
+	return sum
+}
+
+// KeyValue is a single key and its value, used wherever results need a stable order
+type KeyValue[T Number] struct {
+	Key   string
+	Value T
+}
+
+// Each calls fn for every key in lexicographic order, stopping early if fn returns false
+func (s *Store[T]) Each(fn func(key string, val T) bool) {
+	for c := s.Seek(""); c.Valid(); c.Next() {
+		if !fn(c.Key(), c.Value()) {
+			return
+		}
+	}
+}
+
+// Range returns all keys k with startKey <= k < endKey, in lexicographic order.
+// An empty endKey means "no upper bound".
+func (s *Store[T]) Range(startKey, endKey string) []KeyValue[T] {
+	var results []KeyValue[T]
+	for c := s.Seek(startKey); c.Valid(); c.Next() {
+		if endKey != "" && c.Key() >= endKey {
+			break
+		}
+		results = append(results, KeyValue[T]{Key: c.Key(), Value: c.Value()})
+	}
+	return results
+}
+
+// Cursor walks the store's keys in lexicographic order, merging the buffered writes in as it goes.
+// It works on the keys and values as they were when Seek was called; later writes aren't visible to it.
+type Cursor[T Number] struct {
+	keys   []string
+	values []T
+	pos    int
+	// buffered writes from Seek's key on, which take precedence over keys
+	pending    []pendingWrite[T]
+	pendingPos int
+}
+
+// Seek returns a Cursor positioned at the first key >= key
+func (s *Store[T]) Seek(key string) *Cursor[T] {
+	return s.cursor(key, "")
+}
+
+// cursor returns a Cursor positioned at the first key >= start. Only buffered writes with the given prefix are
+// merged in, so it's only right for as long as its keys have that prefix.
+func (s *Store[T]) cursor(start, prefix string) *Cursor[T] {
+	pos, _ := s.find(start)
+	c := &Cursor[T]{keys: s.keys, values: s.values, pos: pos, pending: s.pending(start, prefix)}
+	c.skipDeleted()
+	return c
+}
+
+// skipDeleted moves the cursor past keys that have a buffered delete
+func (c *Cursor[T]) skipDeleted() {
+	for c.pendingPos < len(c.pending) && c.pending[c.pendingPos].deleted {
+		key := c.pending[c.pendingPos].key
+		if c.pos < len(c.keys) && c.keys[c.pos] < key {
+			// the next key isn't the deleted one
+			return
+		}
+		if c.pos < len(c.keys) && c.keys[c.pos] == key {
+			c.pos++
+		}
+		c.pendingPos++
+	}
+}
+
+// fromPending reports whether the current key is a buffered write rather than one of the sorted keys
+func (c *Cursor[T]) fromPending() bool {
+	return c.pendingPos < len(c.pending) && (c.pos == len(c.keys) || c.pending[c.pendingPos].key <= c.keys[c.pos])
+}
+
+// Valid reports whether the cursor points at a key (false once it has run off the end)
+func (c *Cursor[T]) Valid() bool {
+	return c.pos < len(c.keys) || c.pendingPos < len(c.pending)
+}
+
+// Next moves the cursor to the next key
+func (c *Cursor[T]) Next() {
+	switch {
+	case c.fromPending():
+		// the buffered write replaces the sorted key, if there is one
+		if c.pos < len(c.keys) && c.keys[c.pos] == c.pending[c.pendingPos].key {
+			c.pos++
+		}
+		c.pendingPos++
+	case c.Valid():
+		c.pos++
+	}
+	c.skipDeleted()
+}
+
+// Key returns the key the cursor points at
+func (c *Cursor[T]) Key() string {
+	if c.fromPending() {
+		return c.pending[c.pendingPos].key
+	}
+	return c.keys[c.pos]
+}
+
+// Value returns the value for the key the cursor points at
+func (c *Cursor[T]) Value() T {
+	if c.fromPending() {
+		return c.pending[c.pendingPos].value
+	}
+	return c.values[c.pos]
+}
+
+func (s *Store[T]) String() string {
+	var resultString string
+	if s == nil {
+		return ""
+	}
+	s.Each(func(key string, val T) bool {
+		resultString = resultString + fmt.Sprintf("\n%s - %v", key, val)
+		return true
+	})
+	return resultString
+}
+
+// Update sets key to whatever fn returns, given the current value (and whether there is one)
+func (s *Store[T]) Update(key string, fn func(old T, exists bool) T) T {
+	exists, old := s.Search(key)
+	newVal := fn(old, exists)
+	s.Insert(key, newVal)
+	return newVal
+}
+
+// Increment adds delta to the value for key (starting from zero if there isn't one) and returns the new value
+func (s *Store[T]) Increment(key string, delta T) T {
+	return s.Update(key, func(old T, exists bool) T { return old + delta })
+}
+
+// CompareAndSwap sets key to new only if it currently holds old, and reports whether it did.
+// Missing keys are never swapped.
+func (s *Store[T]) CompareAndSwap(key string, old, new T) bool {
+	exists, current := s.Search(key)
+	if !exists || current != old {
+		return false
+	}
+	s.Insert(key, new)
+	return true
+}
+
+// LoadOrStore returns the existing value for key if there is one (and loaded=true).
+// Otherwise it stores val and returns it.
+func (s *Store[T]) LoadOrStore(key string, val T) (actual T, loaded bool) {
+	if exists, current := s.Search(key); exists {
+		return current, true
+	}
+	s.Insert(key, val)
+	return val, false
+}
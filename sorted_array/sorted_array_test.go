@@ -0,0 +1,246 @@
+package sorted_array
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestInsertAndSearch(t *testing.T) {
+	store := New[int64]()
+	store.Insert("replace", 100)
+	store.Insert("replace", 300)
+	found, val := store.Search("replace")
+	if !found || val != 300 {
+		t.Errorf("expected replacements to work. found=%v, val=%d", found, val)
+	}
+
+	// enough keys to go through several merges, inserted out of order
+	for i := 999; i >= 0; i-- {
+		store.Insert(fmt.Sprintf("key.%04d", i), int64(i))
+	}
+	for i := 0; i < 1000; i++ {
+		found, val := store.Search(fmt.Sprintf("key.%04d", i))
+		if !found || val != int64(i) {
+			t.Errorf("expected key.%04d to be %d, found=%v, val=%d", i, i, found, val)
+		}
+	}
+	if store.Len() != 1001 {
+		t.Errorf("expected 1001 keys, got %d", store.Len())
+	}
+}
+
+func TestDelete(t *testing.T) {
+	store := New[int64]()
+	store.Insert("a", 1)
+	store.Insert("b", 2)
+	store.merge()
+	store.Insert("c", 3)
+
+	if !store.Delete("a") || !store.Delete("c") {
+		t.Errorf("expected merged and buffered keys to be deleted")
+	}
+	if store.Delete("a") || store.Delete("nope") {
+		t.Errorf("expected deleting a missing key to report false")
+	}
+	if found, _ := store.Search("a"); found {
+		t.Errorf("expected a to be gone")
+	}
+	store.Insert("a", 10)
+	expected := []KeyValue[int64]{{"a", 10}, {"b", 2}}
+	if results := store.Range("", ""); !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected %v, got %v", expected, results)
+	}
+}
+
+func TestSearchPrefix(t *testing.T) {
+	store := New[int64]()
+	store.Insert("business_summary.departments.finance", 0)
+	store.Insert("business_summary.departments.software", 100)
+	store.Insert("business_summary.revenue.top_line", 70)
+	store.Insert("business_summary.revenue.net", 50)
+	store.Insert("business_summary", 1)
+	store.Insert("business_summaryz", 2)
+	store.Insert("a", 3)
+
+	results := store.SearchPrefix("business_summary.revenue")
+	expected := map[string]int64{"business_summary.revenue.top_line": 70, "business_summary.revenue.net": 50}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected %v, got %v", expected, results)
+	}
+	if results := store.SearchPrefix("business_summary"); len(results) != 6 {
+		t.Errorf("expected 6 results, got %v", results)
+	}
+	if results := store.SearchPrefix("zzz"); len(results) != 0 {
+		t.Errorf("expected no results, got %v", results)
+	}
+
+	found, sum := store.AggregateDescendants("business_summary.departments", Sum[int64])
+	if !found || sum != 100 {
+		t.Errorf("expected departments to sum to 100, found=%v, sum=%f", found, sum)
+	}
+	if found, _ := store.AggregateDescendants("nope", Sum[int64]); found {
+		t.Errorf("expected no aggregate for a missing prefix")
+	}
+}
+
+func TestOrderedIteration(t *testing.T) {
+	store := New[int]()
+	for i, key := range []string{"aa", "ab", "abc", "b", "ba", "c"} {
+		store.Insert(key, i)
+	}
+
+	results := store.Range("ab", "ba")
+	expected := []KeyValue[int]{{"ab", 1}, {"abc", 2}, {"b", 3}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected Range to return %v, got %v", expected, results)
+	}
+
+	c := store.Seek("abd")
+	// writes after Seek don't show up in the cursor
+	store.Insert("abe", 10)
+	if !c.Valid() || c.Key() != "b" {
+		t.Errorf("expected Seek(abd) to land on b")
+	}
+
+	page, token := store.SearchPrefixPage("a", PageOptions{Limit: 2})
+	if len(page) != 2 || token != "ab" {
+		t.Errorf("expected a first page of 2 ending at ab, got %v %q", page, token)
+	}
+	page, token = store.SearchPrefixPage("a", PageOptions{Limit: 2, After: token})
+	expected = []KeyValue[int]{{"abc", 2}, {"abe", 10}}
+	if !reflect.DeepEqual(page, expected) || token != "" {
+		t.Errorf("expected the last page %v, got %v %q", expected, page, token)
+	}
+}
+
+func TestReadModifyWrite(t *testing.T) {
+	store := New[int]()
+	if store.Increment("count", 2) != 2 || store.Increment("count", 3) != 5 {
+		t.Errorf("expected increments to add up")
+	}
+	if store.CompareAndSwap("count", 4, 10) || !store.CompareAndSwap("count", 5, 10) {
+		t.Errorf("expected only the matching CompareAndSwap to succeed")
+	}
+	if val, loaded := store.LoadOrStore("count", 1); !loaded || val != 10 {
+		t.Errorf("expected LoadOrStore to load 10, got %d %v", val, loaded)
+	}
+	if val, loaded := store.LoadOrStore("new", 1); loaded || val != 1 {
+		t.Errorf("expected LoadOrStore to store 1, got %d %v", val, loaded)
+	}
+}
+
+func TestStats(t *testing.T) {
+	store := New[int64]()
+	store.Insert("profits.revenue.net", 3)
+	store.Insert("profits.revenue.taxes", -200)
+
+	// buffered writes count too
+	stats := store.Stats()
+	if stats.Keys != 2 || stats.Nodes != 0 || stats.StringBytes != len("profits.revenue.net")+len("profits.revenue.taxes") {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if stats.ValueBytes < 16 || stats.ChildrenBytes < 2*16 {
+		t.Errorf("expected the slices to be counted, got %+v", stats)
+	}
+}
+
+func TestReadsMergeBuffer(t *testing.T) {
+	store := New[int64]()
+	expected := make(map[string]int64)
+	rng := rand.New(rand.NewSource(1))
+	for step := 0; step < 2000; step++ {
+		key := fmt.Sprintf("%c.%d", 'a'+rng.Intn(3), rng.Intn(100))
+		if rng.Intn(3) == 0 {
+			store.Delete(key)
+			delete(expected, key)
+		} else {
+			store.Insert(key, int64(step))
+			expected[key] = int64(step)
+		}
+		if step%97 != 0 {
+			continue
+		}
+
+		buffered, merged := len(store.buffer), len(store.keys)
+		if store.Len() != len(expected) {
+			t.Fatalf("step %d: expected %d keys, got %d", step, len(expected), store.Len())
+		}
+		for _, prefix := range []string{"a", "b.1", "c.99", "d"} {
+			want := make(map[string]int64)
+			var wantKeys []string
+			for key, val := range expected {
+				if strings.HasPrefix(key, prefix) {
+					want[key] = val
+					wantKeys = append(wantKeys, key)
+				}
+			}
+			sort.Strings(wantKeys)
+			if got := store.SearchPrefix(prefix); !reflect.DeepEqual(got, want) {
+				t.Fatalf("step %d: expected SearchPrefix(%q) to be %v, got %v", step, prefix, want, got)
+			}
+			var gotKeys []string
+			store.SearchPrefixFunc(prefix, func(key string, val int64) bool {
+				gotKeys = append(gotKeys, key)
+				return true
+			})
+			if len(gotKeys) != len(wantKeys) || (len(wantKeys) > 0 && !reflect.DeepEqual(gotKeys, wantKeys)) {
+				t.Fatalf("step %d: expected SearchPrefixFunc(%q) to visit %v, got %v", step, prefix, wantKeys, gotKeys)
+			}
+			// pages of 7 have to add up to all the keys, in order
+			gotKeys = nil
+			for after := ""; ; {
+				page, next := store.SearchPrefixPage(prefix, PageOptions{Limit: 7, After: after})
+				for _, kv := range page {
+					gotKeys = append(gotKeys, kv.Key)
+				}
+				if next == "" {
+					break
+				}
+				after = next
+			}
+			if len(gotKeys) != len(wantKeys) || (len(wantKeys) > 0 && !reflect.DeepEqual(gotKeys, wantKeys)) {
+				t.Fatalf("step %d: expected pages of %q to be %v, got %v", step, prefix, wantKeys, gotKeys)
+			}
+			if page, _ := store.SearchPrefixPage(prefix, PageOptions{Offset: 3, Limit: 2}); len(wantKeys) > 3 && page[0].Key != wantKeys[3] {
+				t.Fatalf("step %d: expected an offset of 3 to start at %q, got %v", step, wantKeys[3], page)
+			}
+		}
+		if all := store.Range("", ""); len(all) != len(expected) {
+			t.Fatalf("step %d: expected Range to return %d keys, got %d", step, len(expected), len(all))
+		}
+		if len(store.buffer) != buffered || len(store.keys) != merged {
+			t.Fatalf("step %d: expected reads to leave the buffer alone", step)
+		}
+	}
+}
+
+func TestConcurrentReads(t *testing.T) {
+	store := New[int64]()
+	for i := 0; i < 1000; i++ {
+		store.Insert(fmt.Sprintf("key.%04d", i), int64(i))
+	}
+	// some of these are still buffered
+	store.Insert("key.0500", -1)
+	store.Delete("key.0501")
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if results := store.SearchPrefix("key.05"); len(results) != 99 {
+					t.Errorf("expected 99 results, got %d", len(results))
+				}
+				store.Range("key.0400", "key.0600")
+				store.Len()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,26 @@
+package sorted_array
+
+import (
+	"unsafe"
+
+	"github.com/groovemonkey/trie-keys-experiment/stats"
+)
+
+// Stats returns the store's Stats.
+// The store is flat, so it only has keys, the slices (and write buffer) holding them, strings and values.
+// Buffered writes' strings count as well as the merged keys', even where they're for the same key.
+func (s *Store[T]) Stats() stats.Stats {
+	var zero T
+	st := stats.Stats{Keys: s.Len()}
+	for _, key := range s.keys {
+		st.StringBytes += len(key)
+	}
+	for key := range s.buffer {
+		st.StringBytes += len(key)
+	}
+	bufferedValues := len(s.buffer) * int(unsafe.Sizeof(zero))
+	st.ValueBytes = cap(s.values)*int(unsafe.Sizeof(zero)) + bufferedValues
+	st.ChildrenBytes = cap(s.keys)*int(unsafe.Sizeof("")) +
+		stats.MapBytes(len(s.buffer), unsafe.Sizeof("")+unsafe.Sizeof(bufferedWrite[T]{})) - bufferedValues
+	return st
+}
@@ -0,0 +1,78 @@
+package stats
+
+// Stats describes how much a store holds, what shape it's in and roughly how much memory it takes, for capacity planning.
+// Every store fills it in the same way, so the numbers can be compared across stores.
+// Byte counts are estimates: they add up the sizes of what the store allocates, but not allocator overhead,
+// and values are only counted by their own size, not anything they point to.
+type Stats struct {
+	// number of keys stored
+	Keys int
+	// number of nodes, including the root (flat stores like a map have none)
+	Nodes int
+	// nodes (other than the root) that don't hold a value and are only there to lead to other keys
+	ValuelessNodes int
+	// depth of the deepest key and the average depth of all keys, where the root's children are at depth 1
+	MaxDepth int
+	AvgDepth float64
+	// Fanout[n] is the number of nodes with n children
+	Fanout map[int]int
+
+	// bytes taken by nodes (not counting their values), by the maps and slices linking them together,
+	// by key strings and by values (including the empty value slots of valueless nodes, where a store has them)
+	NodeBytes     int
+	ChildrenBytes int
+	StringBytes   int
+	ValueBytes    int
+}
+
+// New returns empty Stats, ready for AddNode
+func New() Stats {
+	return Stats{Fanout: make(map[int]int)}
+}
+
+// Bytes is the approximate total memory used
+func (s Stats) Bytes() int {
+	return s.NodeBytes + s.ChildrenBytes + s.StringBytes + s.ValueBytes
+}
+
+// AddNode counts a node at depth with numChildren children, and its key if it has a value
+func (s *Stats) AddNode(depth, numChildren int, hasValue bool) {
+	s.Nodes++
+	s.Fanout[numChildren]++
+	switch {
+	case hasValue:
+		s.AddKeys(depth, 1)
+	case depth > 0:
+		s.ValuelessNodes++
+	}
+}
+
+// AddKeys counts n keys at depth, for stores that keep more than one key in a node
+func (s *Stats) AddKeys(depth, n int) {
+	s.Keys += n
+	s.AvgDepth += float64(n * depth)
+	if n > 0 {
+		s.MaxDepth = max(s.MaxDepth, depth)
+	}
+}
+
+// Finish turns the depth total into an average, once every node has been added
+func (s *Stats) Finish() {
+	if s.Keys > 0 {
+		s.AvgDepth /= float64(s.Keys)
+	}
+}
+
+// MapBytes estimates the memory of a map with n entries of entrySize bytes each.
+// Go's maps keep entries in groups of 8 slots with a control byte per slot, and grow before they're 7/8 full.
+func MapBytes(n int, entrySize uintptr) int {
+	const headerBytes = 48
+	if n == 0 {
+		return headerBytes
+	}
+	slots := 8
+	for slots*7/8 < n {
+		slots *= 2
+	}
+	return headerBytes + slots*(int(entrySize)+1)
+}
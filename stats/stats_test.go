@@ -0,0 +1,57 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAddNode(t *testing.T) {
+	s := New()
+	// a root with two children, one of which leads to a key
+	s.AddNode(0, 2, false)
+	s.AddNode(1, 0, true)
+	s.AddNode(1, 1, false)
+	s.AddNode(2, 0, true)
+	s.AddKeys(3, 2)
+	s.AddKeys(5, 0)
+	s.Finish()
+
+	if s.Keys != 4 || s.Nodes != 4 || s.ValuelessNodes != 1 {
+		t.Errorf("expected 4 keys in 4 nodes with 1 valueless one, got %+v", s)
+	}
+	if s.MaxDepth != 3 || s.AvgDepth != 9.0/4 {
+		t.Errorf("expected depths of 3 and 9/4, got %d and %v", s.MaxDepth, s.AvgDepth)
+	}
+	expected := map[int]int{0: 2, 1: 1, 2: 1}
+	if !reflect.DeepEqual(s.Fanout, expected) {
+		t.Errorf("expected fanout %v, got %v", expected, s.Fanout)
+	}
+}
+
+func TestFinishEmpty(t *testing.T) {
+	s := New()
+	s.Finish()
+	if s.AvgDepth != 0 {
+		t.Errorf("expected an average depth of 0 without keys, got %v", s.AvgDepth)
+	}
+}
+
+func TestBytes(t *testing.T) {
+	s := Stats{NodeBytes: 1, ChildrenBytes: 2, StringBytes: 4, ValueBytes: 8}
+	if s.Bytes() != 15 {
+		t.Errorf("expected 15 bytes, got %d", s.Bytes())
+	}
+}
+
+func TestMapBytes(t *testing.T) {
+	if got := MapBytes(0, 16); got != 48 {
+		t.Errorf("expected an empty map to only take its header, got %d", got)
+	}
+	// 7 entries fit in one group of 8 slots, the 8th needs a second group
+	if got := MapBytes(7, 16); got != 48+8*17 {
+		t.Errorf("expected one group, got %d", got)
+	}
+	if got := MapBytes(8, 16); got != 48+16*17 {
+		t.Errorf("expected two groups, got %d", got)
+	}
+}
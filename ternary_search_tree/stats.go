@@ -0,0 +1,34 @@
+package ternary_search_tree
+
+import (
+	"unsafe"
+
+	"github.com/groovemonkey/trie-keys-experiment/stats"
+)
+
+// Stats walks the whole tree and returns its Stats.
+// Depth and fanout are counted like in a trie: a node's children are all the nodes for the next rune (its eq node
+// and everything reachable from there through lo and hi), and a key's depth is its number of runes.
+// The pointers live in the nodes, so there are no children bytes.
+func (t *Tree[T]) Stats() stats.Stats {
+	var zero T
+	st := stats.New()
+	var visit func(node *treeNode[T], depth int)
+	// visitSiblings visits node and its lo/hi siblings, and returns how many there are
+	var visitSiblings func(node *treeNode[T], depth int) int
+	visitSiblings = func(node *treeNode[T], depth int) int {
+		if node == nil {
+			return 0
+		}
+		visit(node, depth)
+		return 1 + visitSiblings(node.lo, depth) + visitSiblings(node.hi, depth)
+	}
+	visit = func(node *treeNode[T], depth int) {
+		st.AddNode(depth, visitSiblings(node.eq, depth+1), node.HasValue)
+	}
+	visit(t.root, 0)
+	st.Finish()
+	st.ValueBytes = st.Nodes * int(unsafe.Sizeof(zero))
+	st.NodeBytes = st.Nodes*int(unsafe.Sizeof(treeNode[T]{})) - st.ValueBytes
+	return st
+}
@@ -0,0 +1,135 @@
+package ternary_search_tree
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// A ternary search tree stores one rune per node like prefix_trie, but instead of a children map every node
+// has three pointers: lo and hi lead to nodes with a smaller/bigger rune at the same position (a little
+// binary search tree of siblings), and eq leads on to the next position in the key.
+// That's three words per node instead of a map, at the cost of a few more comparisons per rune.
+
+type treeNode[T any] struct {
+	Char  rune
+	Value T
+	// avoid mistaking initialized zero values for intentional zero values
+	HasValue bool
+	lo       *treeNode[T]
+	eq       *treeNode[T]
+	hi       *treeNode[T]
+}
+
+type Tree[T any] struct {
+	// the root doesn't hold a rune, its eq subtree holds the first rune of every key
+	root *treeNode[T]
+}
+
+func New[T any]() *Tree[T] {
+	return &Tree[T]{root: &treeNode[T]{}}
+}
+
+func (t *Tree[T]) Insert(s string, val T) {
+	currentNode := t.root
+	for _, char := range s {
+		// find char among the siblings at this position, or the empty slot where it belongs
+		link := &currentNode.eq
+		for *link != nil && (*link).Char != char {
+			if char < (*link).Char {
+				link = &(*link).lo
+			} else {
+				link = &(*link).hi
+			}
+		}
+		// If there's no such node, create one
+		if *link == nil {
+			*link = &treeNode[T]{Char: char}
+		}
+		currentNode = *link
+	}
+	currentNode.Value = val
+	currentNode.HasValue = true
+}
+
+// Search returns whether or not the search string exists in the Tree, and if it does, the associated node.
+func (t *Tree[T]) Search(s string) (bool, *treeNode[T]) {
+	currentNode := t.root
+	for _, char := range s {
+		child := currentNode.eq
+		for child != nil && child.Char != char {
+			if char < child.Char {
+				child = child.lo
+			} else {
+				child = child.hi
+			}
+		}
+		if child == nil {
+			return false, nil
+		}
+		currentNode = child
+	}
+	// NOTE: we don't care whether this is a valid key (i.e. whether currentNode.HasValue)
+	return true, currentNode
+}
+
+// SearchPrefix returns all (string) Keys with the given prefix, mapped to pointers to their value-containing treeNodes
+func (t *Tree[T]) SearchPrefix(prefix string) map[string]*treeNode[T] {
+	keysAndVals := make(map[string]*treeNode[T])
+	if len(prefix) == 0 {
+		return keysAndVals
+	}
+	found, node := t.Search(prefix)
+	if !found {
+		return keysAndVals
+	}
+	if node.HasValue {
+		keysAndVals[prefix] = node
+	}
+	each(node.eq, []byte(prefix), func(key []byte, node *treeNode[T]) bool {
+		keysAndVals[string(key)] = node
+		return true
+	})
+	return keysAndVals
+}
+
+// Each calls fn for every key in lexicographic order, stopping early if fn returns false
+func (t *Tree[T]) Each(fn func(key string, val T) bool) {
+	if t.root.HasValue && !fn("", t.root.Value) {
+		return
+	}
+	each(t.root.eq, nil, func(key []byte, node *treeNode[T]) bool {
+		return fn(string(key), node.Value)
+	})
+}
+
+func (t *Tree[T]) DepthFirstPrint() {
+	each(t.root.eq, nil, func(key []byte, node *treeNode[T]) bool {
+		fmt.Printf("Key: %s Value: %v\n", key, node.Value)
+		return true
+	})
+}
+
+// each calls fn for every value-holding node in the subtree rooted at node, in lexicographic order, and reports
+// false if fn stopped it by returning false.
+// key holds the bytes of the runes before node's position; it's reused, so fn must copy it if it keeps it.
+func each[T any](node *treeNode[T], key []byte, fn func(key []byte, node *treeNode[T]) bool) ([]byte, bool) {
+	for node != nil {
+		// smaller siblings first, then this node and everything continuing from it, then bigger siblings
+		var ok bool
+		if key, ok = each(node.lo, key, fn); !ok {
+			return key, false
+		}
+		n := len(key)
+		key = utf8.AppendRune(key, node.Char)
+		if node.HasValue && !fn(key, node) {
+			return key[:n], false
+		}
+		if key, ok = each(node.eq, key, fn); !ok {
+			return key[:n], false
+		}
+		key = key[:n]
+		// the hi subtree is a tail call, so just loop
+		node = node.hi
+	}
+	return key, true
+}
@@ -0,0 +1,98 @@
+package ternary_search_tree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInsertAndSearch(t *testing.T) {
+	tree := New[int]()
+	tree.Insert("profits.revenue", 1)
+	tree.Insert("profits", 2)
+	tree.Insert("héllo", 3)
+	tree.Insert("hello", 4)
+	tree.Insert("profits", 5)
+
+	for key, expected := range map[string]int{"profits.revenue": 1, "profits": 5, "héllo": 3, "hello": 4} {
+		found, node := tree.Search(key)
+		if !found || !node.HasValue || node.Value != expected {
+			t.Errorf("expected %q to be %d, got %v %v", key, expected, found, node)
+		}
+	}
+
+	// a prefix of a key is found, but doesn't hold a value
+	found, node := tree.Search("prof")
+	if !found || node.HasValue {
+		t.Errorf("expected prof to be found without a value, got %v %v", found, node)
+	}
+	if found, _ := tree.Search("profitsx"); found {
+		t.Errorf("expected profitsx not to be found")
+	}
+}
+
+func TestSearchPrefix(t *testing.T) {
+	tree := New[int]()
+	for i, key := range []string{"profits", "profits.revenue", "profits.revenue.net", "profits.loss", "profit", "héllo", "a"} {
+		tree.Insert(key, i)
+	}
+
+	results := tree.SearchPrefix("profits")
+	if len(results) != 4 {
+		t.Errorf("expected 4 results, got %d", len(results))
+	}
+	for _, key := range []string{"profits", "profits.revenue", "profits.revenue.net", "profits.loss"} {
+		if _, ok := results[key]; !ok {
+			t.Errorf("expected %q in the results", key)
+		}
+	}
+	if results := tree.SearchPrefix("hé"); len(results) != 1 || results["héllo"].Value != 5 {
+		t.Errorf("expected héllo to be found by a multi-byte prefix, got %v", results)
+	}
+	if results := tree.SearchPrefix("nope"); len(results) != 0 {
+		t.Errorf("expected no results, got %v", results)
+	}
+}
+
+func TestEach(t *testing.T) {
+	tree := New[int]()
+	for i, key := range []string{"b", "héllo", "a", "", "ab", "hello"} {
+		tree.Insert(key, i)
+	}
+	var keys []string
+	tree.Each(func(key string, val int) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if expected := []string{"", "a", "ab", "b", "hello", "héllo"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %v, got %v", expected, keys)
+	}
+	keys = nil
+	tree.Each(func(key string, val int) bool {
+		keys = append(keys, key)
+		return len(keys) < 3
+	})
+	if expected := []string{"", "a", "ab"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected to stop after %v, got %v", expected, keys)
+	}
+}
+
+func TestStats(t *testing.T) {
+	tree := New[int]()
+	for _, key := range []string{"ab", "abc", "ad"} {
+		tree.Insert(key, 1)
+	}
+	stats := tree.Stats()
+	if stats.Keys != 3 || stats.Nodes != 5 || stats.ValuelessNodes != 1 {
+		t.Errorf("expected 3 keys in 5 nodes with 1 valueless one, got %+v", stats)
+	}
+	if stats.MaxDepth != 3 || stats.AvgDepth != 7.0/3 {
+		t.Errorf("expected depths of 3 and 7/3, got %d and %v", stats.MaxDepth, stats.AvgDepth)
+	}
+	expected := map[int]int{0: 2, 1: 2, 2: 1}
+	if !reflect.DeepEqual(stats.Fanout, expected) {
+		t.Errorf("expected fanout %v, got %v", expected, stats.Fanout)
+	}
+	if stats.NodeBytes <= 0 || stats.ChildrenBytes != 0 || stats.ValueBytes <= 0 {
+		t.Errorf("unexpected bytes %+v", stats)
+	}
+}
@@ -0,0 +1,243 @@
+package workload
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+)
+
+// A workload is a keyspace shaped like a metric namespace (dot-separated keys, a handful of segment names
+// reused at every level) plus a stream of operations on it. Some keys are a lot hotter than others, the way
+// dashboards keep hitting the same few metrics, so operations pick keys from a Zipf distribution.
+//
+// Everything is generated from Config.Seed, so the same Config always gives the same keys and operations.
+
+// Config describes the keyspace and the mix of operations
+type Config struct {
+	// number of distinct keys, at least 1. It's capped at what MaxDepth and Fanout allow.
+	Keys int
+	// every key has between MinDepth and MaxDepth segments (both at least 1)
+	MinDepth, MaxDepth int
+	// Fanout[i] is how many different segment names there are at depth i (counting from 0). Every node at that
+	// depth picks its children from the same names, so e.g. "revenue" shows up under many parents.
+	// Levels past the end of Fanout use its last entry.
+	Fanout []int
+	// lengths of the generated segment names
+	SegmentLength Lengths
+	// how skewed access is: Keys()[i] is picked with probability proportional to 1/(i+1)^ZipfS.
+	// It must be bigger than 1 for a skew, anything else picks keys uniformly.
+	ZipfS float64
+	// relative frequency of each kind of operation; they don't have to add up to 1.
+	// If they're all 0 every operation is a read.
+	ReadRatio, WriteRatio, PrefixRatio float64
+	// seeds the random numbers behind both the keys and the operations
+	Seed int64
+}
+
+// Lengths picks the length of a segment name
+type Lengths interface {
+	Length(r *rand.Rand) int
+}
+
+// Fixed makes every segment name the same length
+type Fixed int
+
+func (f Fixed) Length(*rand.Rand) int {
+	return max(int(f), 1)
+}
+
+// Uniform picks lengths between Min and Max (inclusive) with equal probability
+type Uniform struct {
+	Min, Max int
+}
+
+func (u Uniform) Length(r *rand.Rand) int {
+	lo := max(u.Min, 1)
+	return lo + r.Intn(max(u.Max-lo+1, 1))
+}
+
+// Normal picks lengths around Mean, clamped to [Min, Max]
+type Normal struct {
+	Mean, StdDev float64
+	Min, Max     int
+}
+
+func (n Normal) Length(r *rand.Rand) int {
+	length := int(math.Round(r.NormFloat64()*n.StdDev + n.Mean))
+	return min(max(length, n.Min, 1), max(n.Max, 1))
+}
+
+// MetricNamespace is a Config shaped like our metric keys: 3 to 6 segments of mostly short words,
+// a few dozen services at the top and fewer choices further down, with a strong skew towards hot keys
+func MetricNamespace(keys int) Config {
+	return Config{
+		Keys:          keys,
+		MinDepth:      3,
+		MaxDepth:      6,
+		Fanout:        []int{40, 200, 30, 12},
+		SegmentLength: Normal{Mean: 8, StdDev: 3, Min: 2, Max: 24},
+		ZipfS:         1.1,
+		ReadRatio:     0.8,
+		WriteRatio:    0.15,
+		PrefixRatio:   0.05,
+		Seed:          1,
+	}
+}
+
+// Kind is what an operation does
+type Kind int
+
+const (
+	// Read looks up Key
+	Read Kind = iota
+	// Write sets Key
+	Write
+	// Prefix searches for everything under Key, which is always a whole number of segments
+	Prefix
+)
+
+// Op is a single operation on the store
+type Op struct {
+	Kind Kind
+	Key  string
+}
+
+// Generator makes a keyspace from a Config and then hands out operations on it
+type Generator struct {
+	cfg  Config
+	rng  *rand.Rand
+	keys []string
+	// nil if access is uniform
+	zipf *rand.Zipf
+	// cumulative ratios of reads and writes, the rest are prefix queries
+	readUpTo, writeUpTo float64
+}
+
+// separator between segments, the same as the chunked trie uses
+const separator = "."
+
+// New generates the keyspace for cfg
+func New(cfg Config) *Generator {
+	cfg.Keys = max(cfg.Keys, 1)
+	cfg.MinDepth = max(cfg.MinDepth, 1)
+	cfg.MaxDepth = max(cfg.MaxDepth, cfg.MinDepth)
+	if len(cfg.Fanout) == 0 {
+		cfg.Fanout = []int{10}
+	}
+	if cfg.SegmentLength == nil {
+		cfg.SegmentLength = Fixed(8)
+	}
+	g := &Generator{cfg: cfg, rng: rand.New(rand.NewSource(cfg.Seed))}
+
+	vocabulary := make([][]string, cfg.MaxDepth)
+	for depth := range vocabulary {
+		vocabulary[depth] = g.segmentNames(g.fanout(depth))
+	}
+	g.keys = g.makeKeys(vocabulary, min(cfg.Keys, g.maxKeys()))
+
+	if cfg.ZipfS > 1 && len(g.keys) > 1 {
+		g.zipf = rand.NewZipf(g.rng, cfg.ZipfS, 1, uint64(len(g.keys)-1))
+	}
+	total := cfg.ReadRatio + cfg.WriteRatio + cfg.PrefixRatio
+	if total <= 0 {
+		cfg.ReadRatio, total = 1, 1
+	}
+	g.readUpTo = cfg.ReadRatio / total
+	g.writeUpTo = (cfg.ReadRatio + cfg.WriteRatio) / total
+	return g
+}
+
+func (g *Generator) fanout(depth int) int {
+	return max(g.cfg.Fanout[min(depth, len(g.cfg.Fanout)-1)], 1)
+}
+
+// maxKeys is how many distinct keys MinDepth, MaxDepth and Fanout allow, capped so it doesn't overflow
+func (g *Generator) maxKeys() int {
+	total, paths := 0, 1
+	for depth := 0; depth < g.cfg.MaxDepth && total < math.MaxInt32; depth++ {
+		paths = min(paths*g.fanout(depth), math.MaxInt32)
+		if depth+1 >= g.cfg.MinDepth {
+			total += paths
+		}
+	}
+	return total
+}
+
+// segmentNames makes n distinct segment names
+func (g *Generator) segmentNames(n int) []string {
+	const letters = "abcdefghijklmnopqrstuvwxyz_"
+	seen := make(map[string]bool, n)
+	names := make([]string, 0, n)
+	for len(names) < n {
+		b := make([]byte, g.cfg.SegmentLength.Length(g.rng))
+		for i := range b {
+			b[i] = letters[g.rng.Intn(len(letters))]
+		}
+		// keep adding letters if the lengths don't leave room for enough distinct names
+		for seen[string(b)] {
+			b = append(b, letters[g.rng.Intn(len(letters))])
+		}
+		seen[string(b)] = true
+		names = append(names, string(b))
+	}
+	return names
+}
+
+// makeKeys picks n distinct random paths through the vocabulary. The order is random too, so the hottest keys
+// (the first ones) aren't next to each other.
+func (g *Generator) makeKeys(vocabulary [][]string, n int) []string {
+	seen := make(map[string]bool, n)
+	keys := make([]string, 0, n)
+	var key strings.Builder
+	for len(keys) < n {
+		key.Reset()
+		depth := g.cfg.MinDepth + g.rng.Intn(g.cfg.MaxDepth-g.cfg.MinDepth+1)
+		for i := 0; i < depth; i++ {
+			if i > 0 {
+				key.WriteString(separator)
+			}
+			key.WriteString(vocabulary[i][g.rng.Intn(len(vocabulary[i]))])
+		}
+		if k := key.String(); !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Keys returns the whole keyspace. Don't modify it.
+func (g *Generator) Keys() []string {
+	return g.keys
+}
+
+// Key picks a key, favouring hot ones if access is skewed
+func (g *Generator) Key() string {
+	if g.zipf != nil {
+		return g.keys[g.zipf.Uint64()]
+	}
+	return g.keys[g.rng.Intn(len(g.keys))]
+}
+
+// Next returns the next operation
+func (g *Generator) Next() Op {
+	key := g.Key()
+	switch p := g.rng.Float64(); {
+	case p < g.readUpTo:
+		return Op{Kind: Read, Key: key}
+	case p < g.writeUpTo:
+		return Op{Kind: Write, Key: key}
+	}
+	// a prefix of a hot key, cut after a random number of segments (but at least one)
+	segments := strings.Split(key, separator)
+	return Op{Kind: Prefix, Key: strings.Join(segments[:1+g.rng.Intn(len(segments))], separator)}
+}
+
+// Ops returns the next n operations
+func (g *Generator) Ops(n int) []Op {
+	ops := make([]Op, n)
+	for i := range ops {
+		ops[i] = g.Next()
+	}
+	return ops
+}
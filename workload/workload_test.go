@@ -0,0 +1,108 @@
+package workload
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKeys(t *testing.T) {
+	cfg := Config{Keys: 2000, MinDepth: 2, MaxDepth: 4, Fanout: []int{5, 20, 10}, SegmentLength: Uniform{3, 6}, Seed: 42}
+	g := New(cfg)
+	keys := g.Keys()
+	if len(keys) != 2000 {
+		t.Errorf("expected 2000 keys, got %d", len(keys))
+	}
+
+	seen := make(map[string]bool)
+	names := make([]map[string]bool, cfg.MaxDepth)
+	for i := range names {
+		names[i] = make(map[string]bool)
+	}
+	for _, key := range keys {
+		if seen[key] {
+			t.Errorf("expected distinct keys, got %q twice", key)
+		}
+		seen[key] = true
+		segments := strings.Split(key, ".")
+		if len(segments) < 2 || len(segments) > 4 {
+			t.Errorf("expected 2 to 4 segments, got %q", key)
+		}
+		for depth, segment := range segments {
+			names[depth][segment] = true
+			if len(segment) < 3 {
+				t.Errorf("expected segments of at least 3 bytes, got %q in %q", segment, key)
+			}
+		}
+	}
+	// the last fanout is reused for deeper levels
+	for depth, max := range []int{5, 20, 10, 10} {
+		if len(names[depth]) > max {
+			t.Errorf("expected at most %d names at depth %d, got %d", max, depth, len(names[depth]))
+		}
+	}
+
+	if again := New(cfg).Keys(); !reflect.DeepEqual(again, keys) {
+		t.Errorf("expected the same seed to give the same keys")
+	}
+	// 2 + 2*3 = 8 possible keys
+	if small := New(Config{Keys: 100, MinDepth: 1, MaxDepth: 2, Fanout: []int{2, 3}}); len(small.Keys()) != 8 {
+		t.Errorf("expected the keyspace to be capped at 8 keys, got %v", small.Keys())
+	}
+}
+
+func TestLengths(t *testing.T) {
+	g := New(Config{})
+	for i := 0; i < 1000; i++ {
+		if n := (Normal{Mean: 8, StdDev: 5, Min: 2, Max: 12}).Length(g.rng); n < 2 || n > 12 {
+			t.Errorf("expected a normal length within [2, 12], got %d", n)
+		}
+		if n := (Uniform{Min: 4, Max: 4}).Length(g.rng); n != 4 {
+			t.Errorf("expected a uniform length of 4, got %d", n)
+		}
+	}
+	if Fixed(0).Length(g.rng) != 1 {
+		t.Errorf("expected lengths of at least 1")
+	}
+}
+
+func TestOps(t *testing.T) {
+	g := New(MetricNamespace(10000))
+	counts := make(map[Kind]int)
+	hits := make(map[string]int)
+	for _, op := range g.Ops(100000) {
+		counts[op.Kind]++
+		if op.Kind != Prefix {
+			hits[op.Key]++
+		}
+	}
+	// 80/15/5, give or take
+	if counts[Read] < 78000 || counts[Read] > 82000 || counts[Write] < 13500 || counts[Write] > 16500 || counts[Prefix] < 4000 || counts[Prefix] > 6000 {
+		t.Errorf("expected an 80/15/5 mix, got %v", counts)
+	}
+	// with a Zipf skew the hottest key gets far more than its share
+	if hot := hits[g.Keys()[0]]; hot < 100*100000/10000 {
+		t.Errorf("expected the hottest key to get at least 100x its share, got %d hits", hot)
+	}
+
+	// prefixes are whole segments of some key
+	keys := make(map[string]bool)
+	for _, key := range g.Keys() {
+		for i := range key {
+			if key[i] == '.' {
+				keys[key[:i]] = true
+			}
+		}
+		keys[key] = true
+	}
+	for _, op := range g.Ops(1000) {
+		if !keys[op.Key] {
+			t.Errorf("expected %q to be a key or a whole-segment prefix of one", op.Key)
+		}
+	}
+
+	uniform := New(Config{Keys: 100, ZipfS: 0})
+	if ops := uniform.Ops(10); ops[0].Kind != Read {
+		t.Errorf("expected only reads when no ratios are set, got %v", ops)
+	}
+}